@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+func BuildBinaryCommand() *cli.Command {
+	c := &cli.Command{
+		Name:    "binary",
+		Aliases: []string{"binaries", "b"},
+		Usage:   "commands for managing the registry of server binaries nodes can pin",
+	}
+
+	c.Subcommands = append(c.Subcommands, BuildBinaryAddCommand())
+	c.Subcommands = append(c.Subcommands, BuildBinaryBuildCommand())
+	c.Subcommands = append(c.Subcommands, BuildBinaryListCommand())
+	c.Subcommands = append(c.Subcommands, BuildBinaryRemoveCommand())
+
+	return c
+}
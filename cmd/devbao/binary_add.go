@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildBinaryAddCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "add",
+		Aliases:   []string{"a"},
+		ArgsUsage: "<name> <path>",
+		Usage:     "register a server binary under a name, detecting its version",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "overwrite an existing binary of the same name",
+			},
+		},
+
+		Action: RunBinaryAddCommand,
+	}
+
+	return c
+}
+
+func RunBinaryAddCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 2 {
+		return fmt.Errorf("missing required positional arguments:\n\t<name>, the name to register the binary under\n\t<path>, the path to the server binary")
+	}
+
+	name := cCtx.Args().First()
+	if !cCtx.Bool("force") {
+		present, err := bao.BinaryExists(name)
+		if err != nil {
+			return fmt.Errorf("error checking if binary exists: %w", err)
+		}
+
+		if present {
+			return fmt.Errorf("refusing to override binary %v", name)
+		}
+	}
+
+	binary, err := bao.AddBinary(name, cCtx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("registered %v: %v %v (%v)\n", binary.Name, binary.Product, binary.Version, binary.Path)
+
+	return nil
+}
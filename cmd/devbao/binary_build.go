@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildBinaryBuildCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "build",
+		Aliases:   []string{"b"},
+		ArgsUsage: "<name>",
+		Usage:     "build the server from a local source checkout and register it",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "source",
+				Required: true,
+				Usage:    "path to the source checkout",
+			},
+			&cli.BoolFlag{
+				Name:  "make",
+				Usage: "build with `make bin` rather than `go build`",
+			},
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "overwrite an existing binary of the same name",
+			},
+		},
+
+		Action: RunBinaryBuildCommand,
+	}
+
+	return c
+}
+
+func RunBinaryBuildCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the name to register the binary under")
+	}
+
+	name := cCtx.Args().First()
+	if !cCtx.Bool("force") {
+		present, err := bao.BinaryExists(name)
+		if err != nil {
+			return fmt.Errorf("error checking if binary exists: %w", err)
+		}
+
+		if present {
+			return fmt.Errorf("refusing to override binary %v", name)
+		}
+	}
+
+	fmt.Printf("building %v from %v...\n", name, cCtx.String("source"))
+	binary, err := bao.BuildBinary(name, cCtx.String("source"), cCtx.Bool("make"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("registered %v: %v %v (commit %v)\n", binary.Name, binary.Product, binary.Version, binary.Commit)
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildBinaryListCommand() *cli.Command {
+	c := &cli.Command{
+		Name:    "list",
+		Aliases: []string{"l"},
+		Usage:   "list registered binaries",
+
+		Action: RunBinaryListCommand,
+	}
+
+	return c
+}
+
+func RunBinaryListCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Present() {
+		return fmt.Errorf("unexpected positional argument -- this command takes none: `%v`", cCtx.Args().First())
+	}
+
+	binaries, err := bao.ListBinaries()
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for index, name := range binaries {
+		binary, err := bao.LoadBinary(name)
+		if err != nil {
+			return fmt.Errorf("failed to load binary %d (`%v`): %w", index, name, err)
+		}
+
+		commit := ""
+		if binary.Commit != "" {
+			commit = fmt.Sprintf(" [commit: %v]", binary.Commit)
+		}
+
+		lines = append(lines, fmt.Sprintf(" - %v: %v %v (%v)%v", binary.Name, binary.Product, binary.Version, binary.Path, commit))
+	}
+
+	fmt.Println(strings.Join(lines, "\n"))
+
+	return nil
+}
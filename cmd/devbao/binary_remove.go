@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildBinaryRemoveCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "remove",
+		Aliases:   []string{"r"},
+		ArgsUsage: "<name>",
+		Usage:     "unregister a binary; binaries added from elsewhere are left in place",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "remove the binary even if nodes have pinned it",
+			},
+		},
+
+		Action: RunBinaryRemoveCommand,
+	}
+
+	return c
+}
+
+func RunBinaryRemoveCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the name of the binary")
+	}
+
+	name := cCtx.Args().First()
+	binary, err := bao.LoadBinary(name)
+	if err != nil {
+		return fmt.Errorf("error loading binary: %w", err)
+	}
+
+	nodes, err := bao.ListNodes()
+	if err != nil {
+		return err
+	}
+
+	var pinned []string
+	for index, nodeName := range nodes {
+		node, err := bao.LoadNode(nodeName)
+		if err != nil {
+			return fmt.Errorf("failed to load node %d (`%v`): %w", index, nodeName, err)
+		}
+
+		if node.Binary == name {
+			pinned = append(pinned, nodeName)
+		}
+	}
+
+	if len(pinned) > 0 && !cCtx.Bool("force") {
+		return fmt.Errorf("refusing to remove binary %v pinned by nodes: %v", name, strings.Join(pinned, ", "))
+	}
+
+	return binary.Remove()
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -11,12 +15,101 @@ func BuildClusterCommand() *cli.Command {
 		Usage:   "commands for managing clusters",
 	}
 
+	c.Subcommands = append(c.Subcommands, BuildClusterAutopilotCommand())
 	c.Subcommands = append(c.Subcommands, BuildClusterBuildCommand())
 	c.Subcommands = append(c.Subcommands, BuildClusterCleanCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterDelayCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterDropCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterExportCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterFailoverCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterHealCommand())
 	c.Subcommands = append(c.Subcommands, BuildClusterJoinCommand())
 	c.Subcommands = append(c.Subcommands, BuildClusterListCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterPartitionCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterProxyCommand())
 	c.Subcommands = append(c.Subcommands, BuildClusterRemoveCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterResumeCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterRollingRestartCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterScaleCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterSealCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterSetBinaryCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterSnapshotCommand())
 	c.Subcommands = append(c.Subcommands, BuildClusterStartCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterStatusCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterStopCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterUnsealCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterUpgradeCommand())
 
 	return c
 }
+
+// PrintClusterNodeResults reports the outcome of an operation on each
+// member, returning an error if any of them failed.
+func PrintClusterNodeResults(results []*bao.ClusterNodeResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed += 1
+			fmt.Printf(" - %v: failed: %v\n", result.Name, result.Err)
+			continue
+		}
+
+		fmt.Printf(" - %v: %v\n", result.Name, result.Message)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("operation failed on %d of %d nodes", failed, len(results))
+	}
+
+	return nil
+}
+
+// LoadClusterArg loads the cluster named by the first positional argument.
+func LoadClusterArg(cCtx *cli.Context) (*bao.Cluster, error) {
+	if !cCtx.Args().Present() {
+		return nil, fmt.Errorf("missing required positional argument: <name>, the name of the cluster")
+	}
+
+	cluster, err := bao.LoadCluster(cCtx.Args().First())
+	if err != nil {
+		return nil, fmt.Errorf("error loading cluster: %w", err)
+	}
+
+	return cluster, nil
+}
+
+// LoadFaultCluster loads the cluster named by the first positional argument,
+// ensuring it was started with a fault-injection proxy and that the proxy
+// is running.
+func LoadFaultCluster(cCtx *cli.Context) (*bao.Cluster, error) {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	if cluster.Proxy == nil {
+		return nil, fmt.Errorf("cluster %v was not started with --fault-proxy", cluster.Name)
+	}
+
+	if !cluster.ProxyRunning() {
+		fmt.Printf("resuming fault-injection proxy...\n")
+		if err := cluster.StartProxy(); err != nil {
+			return nil, err
+		}
+	}
+
+	return cluster, nil
+}
+
+// PrintFaults lists the fault rules currently in effect.
+func PrintFaults(faults *bao.Faults) {
+	if len(faults.Rules) == 0 {
+		fmt.Println("no faults in effect")
+		return
+	}
+
+	fmt.Println("faults in effect:")
+	for _, rule := range faults.Rules {
+		fmt.Printf(" - %v\n", rule)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterAutopilotCommand() *cli.Command {
+	c := &cli.Command{
+		Name:    "autopilot",
+		Aliases: []string{"ap"},
+		Usage:   "commands for viewing and tuning a cluster's raft autopilot",
+	}
+
+	c.Subcommands = append(c.Subcommands, BuildClusterAutopilotGetCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterAutopilotSetCommand())
+
+	return c
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterAutopilotGetCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "get",
+		ArgsUsage: "<name>",
+		Usage:     "show the autopilot configuration of the cluster",
+
+		Action: RunClusterAutopilotGetCommand,
+	}
+
+	return c
+}
+
+func RunClusterAutopilotGetCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the name of the cluster")
+	}
+
+	clusterName := cCtx.Args().First()
+	cluster, err := bao.LoadCluster(clusterName)
+	if err != nil {
+		return fmt.Errorf("error loading cluster: %w", err)
+	}
+
+	config, err := cluster.GetAutopilotConfig()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("cleanup_dead_servers: %v\n", config.CleanupDeadServers)
+	fmt.Printf("last_contact_threshold: %v\n", config.LastContactThreshold)
+	fmt.Printf("dead_server_last_contact_threshold: %v\n", config.DeadServerLastContactThreshold)
+	fmt.Printf("max_trailing_logs: %v\n", config.MaxTrailingLogs)
+	fmt.Printf("min_quorum: %v\n", config.MinQuorum)
+	fmt.Printf("server_stabilization_time: %v\n", config.ServerStabilizationTime)
+
+	return nil
+}
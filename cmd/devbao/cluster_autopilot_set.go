@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterAutopilotSetCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "set",
+		ArgsUsage: "<name>",
+		Usage:     "update the autopilot configuration of the cluster; unset options are left unchanged",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "cleanup-dead-servers",
+				Usage: "automatically remove servers which stop responding",
+			},
+			&cli.DurationFlag{
+				Name:  "last-contact-threshold",
+				Usage: "time after last contact before a server is considered unhealthy",
+			},
+			&cli.DurationFlag{
+				Name:  "dead-server-last-contact-threshold",
+				Usage: "time after last contact before a server is considered dead and removed",
+			},
+			&cli.IntFlag{
+				Name:  "max-trailing-logs",
+				Usage: "number of log entries a server may lag behind the leader while healthy",
+			},
+			&cli.IntFlag{
+				Name:  "min-quorum",
+				Usage: "minimum number of voters kept when cleaning up dead servers",
+			},
+			&cli.DurationFlag{
+				Name:  "server-stabilization-time",
+				Usage: "time a new server must be healthy before it is promoted to a voter",
+			},
+		},
+
+		Action: RunClusterAutopilotSetCommand,
+	}
+
+	return c
+}
+
+func RunClusterAutopilotSetCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the name of the cluster")
+	}
+
+	fields := map[string]interface{}{}
+	if cCtx.IsSet("cleanup-dead-servers") {
+		fields["cleanup_dead_servers"] = cCtx.Bool("cleanup-dead-servers")
+	}
+
+	for _, flag := range []string{"last-contact-threshold", "dead-server-last-contact-threshold", "server-stabilization-time"} {
+		if cCtx.IsSet(flag) {
+			fields[flagToField(flag)] = cCtx.Duration(flag).String()
+		}
+	}
+
+	for _, flag := range []string{"max-trailing-logs", "min-quorum"} {
+		if cCtx.IsSet(flag) {
+			if cCtx.Int(flag) < 0 {
+				return fmt.Errorf("--%v must not be negative; got %v", flag, cCtx.Int(flag))
+			}
+
+			fields[flagToField(flag)] = cCtx.Int(flag)
+		}
+	}
+
+	if len(fields) == 0 {
+		return fmt.Errorf("no autopilot options given to update")
+	}
+
+	clusterName := cCtx.Args().First()
+	cluster, err := bao.LoadCluster(clusterName)
+	if err != nil {
+		return fmt.Errorf("error loading cluster: %w", err)
+	}
+
+	if err := cluster.SetAutopilotConfig(fields); err != nil {
+		return err
+	}
+
+	fmt.Printf("updated autopilot configuration of cluster %v\n", clusterName)
+
+	return nil
+}
+
+func flagToField(flag string) string {
+	return strings.ReplaceAll(flag, "-", "_")
+}
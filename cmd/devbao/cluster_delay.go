@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+// FaultLinkFlags select the links a fault applies to.
+func FaultLinkFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "from",
+			Usage: "only affect connections opened by these nodes; defaults to all members",
+		},
+		&cli.StringSliceFlag{
+			Name:  "to",
+			Usage: "only affect connections to these nodes; defaults to all members",
+		},
+	}
+}
+
+func BuildClusterDelayCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "delay",
+		ArgsUsage: "<name>",
+		Usage:     "add latency and jitter to raft traffic between nodes",
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:     "latency",
+				Required: true,
+				Usage:    "delay added to each chunk of data forwarded",
+			},
+			&cli.DurationFlag{
+				Name:  "jitter",
+				Usage: "random variation of the latency, in either direction",
+			},
+		},
+
+		Action: RunClusterDelayCommand,
+	}
+
+	c.Flags = append(c.Flags, FaultLinkFlags()...)
+
+	return c
+}
+
+func RunClusterDelayCommand(cCtx *cli.Context) error {
+	cluster, err := LoadFaultCluster(cCtx)
+	if err != nil {
+		return err
+	}
+
+	if cCtx.Duration("jitter") > cCtx.Duration("latency") {
+		return fmt.Errorf("--jitter (%v) must not exceed --latency (%v)", cCtx.Duration("jitter"), cCtx.Duration("latency"))
+	}
+
+	faults, err := cluster.AddFaults(&bao.FaultRule{
+		From:    cCtx.StringSlice("from"),
+		To:      cCtx.StringSlice("to"),
+		Latency: cCtx.Duration("latency"),
+		Jitter:  cCtx.Duration("jitter"),
+	})
+	if err != nil {
+		return err
+	}
+
+	PrintFaults(faults)
+	return nil
+}
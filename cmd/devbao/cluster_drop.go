@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterDropCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "drop",
+		ArgsUsage: "<name>",
+		Usage:     "refuse a percentage of new raft connections between nodes",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:     "percent",
+				Required: true,
+				Usage:    "percentage of new connections to drop, between 0 and 100",
+			},
+		},
+
+		Action: RunClusterDropCommand,
+	}
+
+	c.Flags = append(c.Flags, FaultLinkFlags()...)
+
+	return c
+}
+
+func RunClusterDropCommand(cCtx *cli.Context) error {
+	cluster, err := LoadFaultCluster(cCtx)
+	if err != nil {
+		return err
+	}
+
+	faults, err := cluster.AddFaults(&bao.FaultRule{
+		From:        cCtx.StringSlice("from"),
+		To:          cCtx.StringSlice("to"),
+		DropPercent: cCtx.Int("percent"),
+	})
+	if err != nil {
+		return err
+	}
+
+	PrintFaults(faults)
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterExportCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "export",
+		ArgsUsage: "<cluster-name>",
+		Usage:     "export all cluster members as systemd, docker compose, or kubernetes definitions",
+
+		Action: RunClusterExportCommand,
+	}
+
+	c.Flags = append(c.Flags, ExportFlags()...)
+
+	return c
+}
+
+func RunClusterExportCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 1 {
+		return fmt.Errorf("missing required positional argument:\n\t<cluster-name>, the name of the cluster to export")
+	}
+
+	clusterName := cCtx.Args().First()
+	format := cCtx.String("format")
+	output := cCtx.String("output")
+	if output == "" {
+		output = fmt.Sprintf("%v-%v", clusterName, format)
+	}
+
+	cluster, err := bao.LoadCluster(clusterName)
+	if err != nil {
+		return fmt.Errorf("error loading cluster: %w", err)
+	}
+
+	written, err := bao.ExportCluster(cluster, format, output)
+	PrintExportedFiles(written)
+	if err != nil {
+		return fmt.Errorf("failed to export cluster: %w", err)
+	}
+
+	return nil
+}
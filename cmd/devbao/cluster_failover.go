@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterFailoverCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "failover",
+		ArgsUsage: "<name>",
+		Usage:     "force the leader out and measure how long a new leader takes to be elected",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "mode",
+				Value: bao.FailoverStepDown,
+				Usage: "how to remove the leader: `step-down`, `kill-leader`, or `seal-leader`",
+			},
+			&cli.BoolFlag{
+				Name:  "restore",
+				Usage: "afterwards, restore the old leader and wait for it to rejoin as a standby",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: 60 * time.Second,
+				Usage: "maximum time to wait for a new leader, and for the old leader to rejoin",
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Value: 100 * time.Millisecond,
+				Usage: "time between leadership checks",
+			},
+		},
+
+		Action: RunClusterFailoverCommand,
+	}
+
+	return c
+}
+
+func RunClusterFailoverCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	mode := cCtx.String("mode")
+	if !slices.Contains(bao.FailoverModes, mode) {
+		return fmt.Errorf("unknown value for --mode: valid values are %v; got `%v`", strings.Join(bao.FailoverModes, ", "), mode)
+	}
+
+	timeout := cCtx.Duration("timeout")
+	interval := cCtx.Duration("interval")
+	if timeout <= 0 || interval <= 0 {
+		return fmt.Errorf("--timeout and --interval must be positive")
+	}
+
+	leaderNode, leaderClient, err := cluster.GetLeader()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("current leader: %v\n", leaderNode.Name)
+
+	start := time.Now()
+	switch mode {
+	case bao.FailoverStepDown:
+		fmt.Printf("stepping down %v...\n", leaderNode.Name)
+		err = leaderClient.Sys().StepDown()
+	case bao.FailoverKillLeader:
+		fmt.Printf("killing %v (pid %v)...\n", leaderNode.Name, leaderNode.Exec.Pid)
+		err = leaderNode.Kill()
+	case bao.FailoverSealLeader:
+		fmt.Printf("sealing %v...\n", leaderNode.Name)
+		err = leaderClient.Sys().Seal()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to %v: %w", mode, err)
+	}
+
+	newLeader, _, elapsed, err := cluster.WaitForNewLeader(leaderNode.Name, start, timeout, interval)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v elected as leader after %v\n", newLeader.Name, elapsed.Round(time.Millisecond))
+
+	if !cCtx.Bool("restore") {
+		return nil
+	}
+
+	fmt.Printf("restoring %v...\n", leaderNode.Name)
+	start = time.Now()
+	if err := restoreFailoverLeader(leaderNode, mode); err != nil {
+		return fmt.Errorf("failed to restore %v: %w", leaderNode.Name, err)
+	}
+
+	elapsed, err = cluster.WaitForMemberHealthy(leaderNode.Name, start, timeout, interval)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v rejoined as a standby after %v\n", leaderNode.Name, elapsed.Round(time.Millisecond))
+
+	return nil
+}
+
+// restoreFailoverLeader undoes the action taken against the old leader.
+func restoreFailoverLeader(node *bao.Node, mode string) error {
+	switch mode {
+	case bao.FailoverKillLeader:
+		if err := node.Exec.WaitStopped(); err != nil {
+			return err
+		}
+
+		_, err := EnsureRunning(node)
+		return err
+	case bao.FailoverSealLeader:
+		// Auto-unsealed nodes only unseal again on startup.
+		if len(node.Config.Seals) > 0 {
+			return node.Restart()
+		}
+
+		_, err := node.Unseal()
+		return err
+	}
+
+	// A node which stepped down remains unsealed and rejoins on its own.
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterHealCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "heal",
+		ArgsUsage: "<name>",
+		Usage:     "remove all partitions, delays, and drops injected into the cluster",
+
+		Action: RunClusterHealCommand,
+	}
+
+	return c
+}
+
+func RunClusterHealCommand(cCtx *cli.Context) error {
+	cluster, err := LoadFaultCluster(cCtx)
+	if err != nil {
+		return err
+	}
+
+	faults := &bao.Faults{}
+	if err := cluster.SaveFaults(faults); err != nil {
+		return err
+	}
+
+	PrintFaults(faults)
+	return nil
+}
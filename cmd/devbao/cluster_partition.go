@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterPartitionCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "partition",
+		ArgsUsage: "<name> <node,...> [<node,...>]",
+		Usage:     "cut raft links between two sets of nodes; the second set defaults to all other members",
+
+		Action: RunClusterPartitionCommand,
+	}
+
+	return c
+}
+
+func RunClusterPartitionCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() < 2 {
+		return fmt.Errorf("missing required positional arguments: <name> <node,...>, the cluster and the nodes to partition off")
+	}
+
+	cluster, err := LoadFaultCluster(cCtx)
+	if err != nil {
+		return err
+	}
+
+	side := strings.Split(cCtx.Args().Get(1), ",")
+
+	var other []string
+	if cCtx.Args().Len() > 2 {
+		other = strings.Split(cCtx.Args().Get(2), ",")
+	} else {
+		for _, name := range cluster.Nodes {
+			if !slices.Contains(side, name) {
+				other = append(other, name)
+			}
+		}
+	}
+
+	if len(other) == 0 {
+		return fmt.Errorf("no nodes remain on the other side of the partition")
+	}
+
+	for _, name := range side {
+		if slices.Contains(other, name) {
+			return fmt.Errorf("node %v cannot be on both sides of the partition", name)
+		}
+	}
+
+	faults, err := cluster.AddFaults(
+		&bao.FaultRule{From: side, To: other, Partition: true},
+		&bao.FaultRule{From: other, To: side, Partition: true},
+	)
+	if err != nil {
+		return err
+	}
+
+	PrintFaults(faults)
+	return nil
+}
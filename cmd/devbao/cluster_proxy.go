@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterProxyCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "proxy",
+		ArgsUsage: "<name>",
+		Usage:     "run the cluster's fault-injection proxy in the foreground; started automatically with --fault-proxy",
+		Hidden:    true,
+
+		Action: RunClusterProxyCommand,
+	}
+
+	return c
+}
+
+func RunClusterProxyCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the name of the cluster")
+	}
+
+	return bao.ServeProxy(cCtx.Args().First())
+}
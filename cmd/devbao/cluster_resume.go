@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterResumeCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "resume",
+		ArgsUsage: "<name>",
+		Usage:     "resume and unseal any stopped nodes of the cluster",
+
+		Action: RunClusterResumeCommand,
+	}
+
+	return c
+}
+
+func resumeClusterNode(node *bao.Node) (string, error) {
+	resumed, err := EnsureRunning(node)
+	if err != nil {
+		return "", err
+	}
+
+	if !resumed {
+		return "already running", nil
+	}
+
+	return fmt.Sprintf("resumed as pid %v", node.Exec.Pid), nil
+}
+
+func RunClusterResumeCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	voters, nonVoters, err := cluster.LoadMembers()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("resuming cluster %v...\n", cluster.Name)
+
+	if cluster.Proxy != nil && !cluster.ProxyRunning() {
+		fmt.Printf("resuming fault-injection proxy...\n")
+		if err := cluster.StartProxy(); err != nil {
+			return err
+		}
+	}
+
+	// Voters are resumed first so that a leader can be elected before
+	// non-voters attempt to contact it.
+	results := bao.ForEachNode(voters, resumeClusterNode)
+	if err := PrintClusterNodeResults(results); err != nil {
+		return err
+	}
+
+	leaderNode, _, err := cluster.WaitForLeader()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v selected as leader\n", leaderNode.Name)
+
+	results = bao.ForEachNode(nonVoters, resumeClusterNode)
+	return PrintClusterNodeResults(results)
+}
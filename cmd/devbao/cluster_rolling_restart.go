@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func RollingFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Value: 5 * time.Minute,
+			Usage: "maximum time to wait for each node to become healthy after restarting",
+		},
+		&cli.DurationFlag{
+			Name:  "interval",
+			Value: time.Second,
+			Usage: "time between health checks",
+		},
+	}
+}
+
+func BuildClusterRollingRestartCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "rolling-restart",
+		ArgsUsage: "<name>",
+		Usage:     "restart each member in turn, standbys first and the leader last, waiting for autopilot health in between",
+
+		Action: RunClusterRollingRestartCommand,
+	}
+
+	c.Flags = append(c.Flags, RollingFlags()...)
+
+	return c
+}
+
+func RunClusterRollingRestartCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	return RollCluster(cCtx, cluster, nil)
+}
+
+// RollCluster restarts every member of the cluster one at a time: standbys
+// first, then the leader after stepping it down. Before each restart,
+// prepare (if set) may update the node; after it, autopilot must report
+// the node and cluster healthy before moving on.
+func RollCluster(cCtx *cli.Context, cluster *bao.Cluster, prepare func(node *bao.Node) error) error {
+	timeout := cCtx.Duration("timeout")
+	interval := cCtx.Duration("interval")
+	if timeout <= 0 || interval <= 0 {
+		return fmt.Errorf("--timeout and --interval must be positive")
+	}
+
+	standbys, leaderNode, err := cluster.RollingOrder()
+	if err != nil {
+		return err
+	}
+
+	for _, node := range standbys {
+		if err := rollNode(cluster, node, prepare, timeout, interval); err != nil {
+			return err
+		}
+	}
+
+	if len(standbys) == 0 {
+		fmt.Fprintf(os.Stderr, "[warning] cluster %v has no standbys to take over; it will be unavailable while %v restarts\n", cluster.Name, leaderNode.Name)
+	} else if err := stepDownLeader(cluster, leaderNode, timeout, interval); err != nil {
+		return err
+	}
+
+	if err := rollNode(cluster, leaderNode, prepare, timeout, interval); err != nil {
+		return err
+	}
+
+	fmt.Printf("all %d nodes of cluster %v restarted\n", len(standbys)+1, cluster.Name)
+
+	return nil
+}
+
+// stepDownLeader steps down the leader and waits for another member to be
+// elected in its place.
+func stepDownLeader(cluster *bao.Cluster, leaderNode *bao.Node, timeout time.Duration, interval time.Duration) error {
+	fmt.Printf("stepping down leader %v...\n", leaderNode.Name)
+	client, err := leaderNode.GetClient()
+	if err != nil {
+		return fmt.Errorf("failed to get client for leader %v: %w", leaderNode.Name, err)
+	}
+
+	start := time.Now()
+	if err := client.Sys().StepDown(); err != nil {
+		return fmt.Errorf("failed to step down leader %v: %w", leaderNode.Name, err)
+	}
+
+	newLeader, _, elapsed, err := cluster.WaitForNewLeader(leaderNode.Name, start, timeout, interval)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v elected as leader after %v\n", newLeader.Name, elapsed.Round(time.Millisecond))
+
+	return nil
+}
+
+func rollNode(cluster *bao.Cluster, node *bao.Node, prepare func(node *bao.Node) error, timeout time.Duration, interval time.Duration) error {
+	if prepare != nil {
+		if err := prepare(node); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("restarting %v...\n", node.Name)
+	start := time.Now()
+	if err := node.Restart(); err != nil {
+		return fmt.Errorf("failed to restart node %v: %w", node.Name, err)
+	}
+
+	status, elapsed, err := cluster.WaitForAutopilotHealthy(node.Name, start, timeout, interval)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v healthy after %v\n", node.Name, elapsed.Round(time.Millisecond))
+	PrintUpgradeStatus(status)
+
+	return nil
+}
+
+// PrintUpgradeStatus reports the server version autopilot sees on each
+// member along with any upgrade migration it is tracking.
+func PrintUpgradeStatus(status *bao.ClusterStatus) {
+	var versions []string
+	for _, member := range status.Members {
+		version := member.Version
+		if version == "" {
+			version = "unknown"
+		}
+
+		versions = append(versions, fmt.Sprintf("%v=%v", member.Name, version))
+	}
+
+	fmt.Printf("   versions: %v\n", strings.Join(versions, ", "))
+
+	if upgrade := status.Upgrade; upgrade != nil {
+		fmt.Printf("   autopilot upgrade: %v (target %v; %d of %d voters upgraded)\n", upgrade.Status, upgrade.TargetVersion, len(upgrade.TargetVersionVoters), len(upgrade.TargetVersionVoters)+len(upgrade.OtherVersionVoters))
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterScaleCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "scale",
+		ArgsUsage: "<name>",
+		Usage:     "add or remove voting and non-voting members of the cluster",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "voters",
+				Usage: "desired number of voting members; defaults to the current count",
+			},
+			&cli.IntFlag{
+				Name:  "non-voters",
+				Usage: "desired number of non-voting members; defaults to the current count",
+			},
+		},
+
+		Action: RunClusterScaleCommand,
+	}
+
+	return c
+}
+
+func RunClusterScaleCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	if !cCtx.IsSet("voters") && !cCtx.IsSet("non-voters") {
+		return fmt.Errorf("at least one of --voters or --non-voters is required")
+	}
+
+	voters, nonVoters, err := cluster.LoadMembers()
+	if err != nil {
+		return err
+	}
+
+	targetVoters := len(voters)
+	if cCtx.IsSet("voters") {
+		targetVoters = cCtx.Int("voters")
+	}
+
+	targetNonVoters := len(nonVoters)
+	if cCtx.IsSet("non-voters") {
+		targetNonVoters = cCtx.Int("non-voters")
+	}
+
+	if targetVoters < 1 {
+		return fmt.Errorf("--voters must be at least 1; got %v", targetVoters)
+	}
+
+	if targetNonVoters < 0 {
+		return fmt.Errorf("--non-voters must not be negative; got %v", targetNonVoters)
+	}
+
+	status, err := cluster.Status()
+	if err != nil {
+		return err
+	}
+
+	if status.Leader == "" {
+		return fmt.Errorf("cluster %v has no leader; unable to scale it", cluster.Name)
+	}
+
+	leaderNode, err := bao.LoadNode(status.Leader)
+	if err != nil {
+		return fmt.Errorf("error loading leader node %v: %w", status.Leader, err)
+	}
+
+	// Decide on all removals before touching the cluster, so that a scale
+	// down which would lose quorum is refused outright.
+	var removeVoters []*bao.Node
+	if excess := len(voters) - targetVoters; excess > 0 {
+		candidates := cluster.ScaleDownCandidates(voters, status)
+		if len(candidates) < excess {
+			return fmt.Errorf("unable to remove %d voters without removing the leader %v", excess, status.Leader)
+		}
+
+		removeVoters = candidates[:excess]
+		remaining := candidates[excess:]
+		remaining = append(remaining, leaderNode)
+
+		quorum := targetVoters/2 + 1
+		if healthy := cluster.HealthyVoters(remaining, status); healthy < quorum {
+			return fmt.Errorf("scaling to %d voters would leave %d healthy voters, below the quorum of %d", targetVoters, healthy, quorum)
+		}
+	}
+
+	var removeNonVoters []*bao.Node
+	if excess := len(nonVoters) - targetNonVoters; excess > 0 {
+		removeNonVoters = cluster.ScaleDownCandidates(nonVoters, status)[:excess]
+	}
+
+	if targetVoters%2 == 0 && targetVoters != len(voters) {
+		fmt.Fprintf(os.Stderr, "[warning] an even number of voters (%d) tolerates no more failures than %d voters\n", targetVoters, targetVoters-1)
+	}
+
+	// Non-voters go first as they never affect quorum.
+	for _, node := range append(removeNonVoters, removeVoters...) {
+		fmt.Printf("removing %v from cluster...\n", node.Name)
+		if err := removeClusterMember(cluster, node); err != nil {
+			return err
+		}
+	}
+
+	for count := len(voters); count < targetVoters; count++ {
+		if err := addClusterMember(cluster, leaderNode, voters[0], false); err != nil {
+			return err
+		}
+	}
+
+	for count := len(nonVoters); count < targetNonVoters; count++ {
+		template := leaderNode
+		if len(nonVoters) > 0 {
+			template = nonVoters[0]
+		}
+
+		if err := addClusterMember(cluster, leaderNode, template, true); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("cluster %v scaled to %d voters and %d non-voters\n", cluster.Name, targetVoters, targetNonVoters)
+
+	return nil
+}
+
+// addClusterMember builds a new member modeled after template, starts it,
+// and joins it to the cluster.
+func addClusterMember(cluster *bao.Cluster, leader *bao.Node, template *bao.Node, nonVoter bool) error {
+	node, err := cluster.BuildNextMember(template, nonVoter)
+	if err != nil {
+		return fmt.Errorf("failed to build new member: %w", err)
+	}
+
+	fmt.Printf("starting %v...\n", node.Name)
+	if err := node.Start(); err != nil {
+		return fmt.Errorf("failed to start node %v: %w", node.Name, err)
+	}
+
+	// Give time for the node to come up.
+	time.Sleep(500 * time.Millisecond)
+
+	if cluster.RetryJoin {
+		if err := registerRetryJoinNode(cluster, leader, node); err != nil {
+			return err
+		}
+
+		return cluster.UpdateRetryJoin()
+	}
+
+	fmt.Printf("joining %v to cluster...\n", node.Name)
+	if err := cluster.JoinNodeHACluster(node); err != nil {
+		return fmt.Errorf("failed to join node %v to cluster: %w", node.Name, err)
+	}
+
+	return nil
+}
+
+// removeClusterMember removes the node from the raft configuration before
+// stopping it and deleting its state.
+func removeClusterMember(cluster *bao.Cluster, node *bao.Node) error {
+	if err := cluster.RemoveNodeHACluster(node); err != nil {
+		return fmt.Errorf("failed to remove node %v from cluster: %w", node.Name, err)
+	}
+
+	if node.Exec != nil && node.Exec.ValidateRunning() == nil {
+		if err := node.Kill(); err != nil {
+			return fmt.Errorf("failed to stop node %v: %w", node.Name, err)
+		}
+
+		if err := node.Exec.WaitStopped(); err != nil {
+			return fmt.Errorf("failed waiting for node %v to stop: %w", node.Name, err)
+		}
+	}
+
+	if err := node.Clean(false); err != nil {
+		return fmt.Errorf("failed to clean up node %v: %w", node.Name, err)
+	}
+
+	return nil
+}
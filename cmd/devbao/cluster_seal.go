@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterSealCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "seal",
+		Aliases:   []string{"x"},
+		ArgsUsage: "<name>",
+		Usage:     "seal all running nodes of the cluster",
+
+		Action: RunClusterSealCommand,
+	}
+
+	return c
+}
+
+func RunClusterSealCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	voters, nonVoters, err := cluster.LoadMembers()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("sealing cluster %v...\n", cluster.Name)
+	results := bao.ForEachNode(append(voters, nonVoters...), func(node *bao.Node) (string, error) {
+		if node.Exec == nil || node.Exec.ValidateRunning() != nil {
+			return "not running", nil
+		}
+
+		client, err := node.GetClient()
+		if err != nil {
+			return "", fmt.Errorf("failed to get client for node: %w", err)
+		}
+
+		status, err := client.Sys().SealStatus()
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch seal status: %w", err)
+		}
+
+		if status.Sealed {
+			return "already sealed", nil
+		}
+
+		if err := client.Sys().Seal(); err != nil {
+			return "", fmt.Errorf("failed to seal node: %w", err)
+		}
+
+		return "sealed", nil
+	})
+
+	return PrintClusterNodeResults(results)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterSetBinaryCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "set-binary",
+		ArgsUsage: "<name> <node> <binary>",
+		Usage:     "swap a single member to another binary, restarting it and waiting for autopilot health",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "no-restart",
+				Usage: "only pin the binary; the member runs it once next restarted",
+			},
+		},
+
+		Action: RunClusterSetBinaryCommand,
+	}
+
+	c.Flags = append(c.Flags, RollingFlags()...)
+
+	return c
+}
+
+func RunClusterSetBinaryCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 3 {
+		return fmt.Errorf("missing required positional arguments:\n\t<name>, the name of the cluster\n\t<node>, the member to swap\n\t<binary>, the registered name or path of the binary")
+	}
+
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	name := cCtx.Args().Get(1)
+	if !slices.Contains(cluster.Nodes, name) {
+		return fmt.Errorf("node %v is not a member of cluster %v", name, cluster.Name)
+	}
+
+	node, err := bao.LoadNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	binary, err := bao.PinBinaryRef(cCtx.Args().Get(2))
+	if err != nil {
+		return err
+	}
+
+	pin := func(node *bao.Node) error {
+		node.PinBinary(binary)
+		if err := node.SaveConfig(); err != nil {
+			return fmt.Errorf("failed to pin binary of node %v: %w", node.Name, err)
+		}
+
+		return nil
+	}
+
+	if cCtx.Bool("no-restart") || node.Exec == nil || node.Exec.ValidateRunning() != nil {
+		return pin(node)
+	}
+
+	timeout := cCtx.Duration("timeout")
+	interval := cCtx.Duration("interval")
+	if timeout <= 0 || interval <= 0 {
+		return fmt.Errorf("--timeout and --interval must be positive")
+	}
+
+	leaderNode, _, err := cluster.GetLeader()
+	if err != nil {
+		return err
+	}
+
+	if leaderNode.Name == node.Name && len(cluster.Nodes) > 1 {
+		if err := stepDownLeader(cluster, leaderNode, timeout, interval); err != nil {
+			return err
+		}
+	}
+
+	return rollNode(cluster, node, pin, timeout, interval)
+}
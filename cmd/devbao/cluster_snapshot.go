@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterSnapshotCommand() *cli.Command {
+	c := &cli.Command{
+		Name:    "snapshot",
+		Aliases: []string{"snap"},
+		Usage:   "commands for saving and restoring a cluster's raft snapshots",
+	}
+
+	c.Subcommands = append(c.Subcommands, BuildClusterSnapshotListCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterSnapshotRestoreCommand())
+	c.Subcommands = append(c.Subcommands, BuildClusterSnapshotSaveCommand())
+
+	return c
+}
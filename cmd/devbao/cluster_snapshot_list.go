@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterSnapshotListCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "list",
+		Aliases:   []string{"l"},
+		ArgsUsage: "<name>",
+		Usage:     "list the cluster's saved raft snapshots, oldest first",
+
+		Action: RunClusterSnapshotListCommand,
+	}
+
+	return c
+}
+
+func RunClusterSnapshotListCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	return PrintSnapshots(cluster.Snapshots())
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterSnapshotRestoreCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "restore",
+		ArgsUsage: "<name> [snapshot]",
+		Usage:     "restore one of the cluster's raft snapshots through its leader, by default the most recent",
+		Flags:     SnapshotRestoreFlags(),
+
+		Action: RunClusterSnapshotRestoreCommand,
+	}
+
+	return c
+}
+
+func RunClusterSnapshotRestoreCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	_, client, err := cluster.GetLeader()
+	if err != nil {
+		return err
+	}
+
+	return RestoreSnapshot(cCtx, cluster.Snapshots(), cCtx.Args().Get(1), client)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/openbao/openbao/api/v2"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterSnapshotSaveCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "save",
+		ArgsUsage: "<name>",
+		Usage:     "save a raft snapshot of the cluster, taken from its leader, under the cluster's directory",
+		Flags:     SnapshotSaveFlags(),
+
+		Action: RunClusterSnapshotSaveCommand,
+	}
+
+	return c
+}
+
+func RunClusterSnapshotSaveCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	return SaveSnapshots(cCtx, cluster.Snapshots(), func() (string, *api.Client, error) {
+		leader, client, err := cluster.GetLeader()
+		if err != nil {
+			return "", nil, err
+		}
+
+		return leader.Name, client, nil
+	})
+}
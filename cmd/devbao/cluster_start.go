@@ -2,8 +2,8 @@ package main
 
 import (
 	"fmt"
-	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -54,6 +54,18 @@ func ClusterInfoFlags() []cli.Flag {
 			Aliases: []string{"p"},
 			Usage:   "profiles to apply to the new node",
 		},
+		&cli.BoolFlag{
+			Name:  "retry-join",
+			Usage: "form the cluster through raft retry_join stanzas pointing at every peer, rather than explicit joins",
+		},
+		&cli.StringSliceFlag{
+			Name:  "binaries",
+			Usage: "registered binaries to run, assigned to nodes in order, as `name[=version]:count`; e.g., `old=2.0.1:2,new=2.1.0:1`",
+		},
+		&cli.BoolFlag{
+			Name:  "fault-proxy",
+			Usage: "route raft traffic between nodes through a local proxy so that faults can be injected with the partition, delay, and drop commands",
+		},
 	}
 }
 
@@ -95,7 +107,20 @@ func RunClusterStartCommand(cCtx *cli.Context) error {
 		return fmt.Errorf("expected at least zero non-voter nodes; got %v", nonVoter)
 	}
 
+	binaries, err := ParseBinariesSpec(cCtx.StringSlice("binaries"))
+	if err != nil {
+		return err
+	}
+
 	count := cCtx.Int("count")
+	if len(binaries) > 0 {
+		if !cCtx.IsSet("count") {
+			count = len(binaries)
+		} else if count != len(binaries) {
+			return fmt.Errorf("--binaries assigns %d nodes, but --count is %v", len(binaries), count)
+		}
+	}
+
 	if count < 1 {
 		return fmt.Errorf("required to have at least one node in the cluster; got %v", count)
 	} else if nonVoter >= count {
@@ -119,85 +144,179 @@ func RunClusterStartCommand(cCtx *cli.Context) error {
 
 	nType := cCtx.String("node-type")
 
-	// Build nodes
-	var nodes []*bao.Node
-	for index := 0; index < count; index++ {
-		nonVoter := index >= (count - nonVoter)
+	var seals []bao.Seal
+	for index, seal := range cCtx.StringSlice("seals") {
+		parsed, err := ParseSeal(seal)
+		if err != nil {
+			return fmt.Errorf("failed parsing seal's uri at index %d (`%v`): %w", index, seal, err)
+		}
+
+		seals = append(seals, parsed)
+	}
+
+	_, err = StartHACluster(clusterName, nType, listen, portBase, count, nonVoter, cCtx.Bool("retry-join"), cCtx.Bool("fault-proxy"), seals, binaries, cCtx.StringSlice("profiles"))
+	return err
+}
+
+// ParseBinariesSpec expands a list of `name[=version]:count` entries into
+// the registered binary of each node, in order. When a version is given,
+// it must match the version of the registered binary.
+func ParseBinariesSpec(specs []string) ([]string, error) {
+	var binaries []string
+	for _, spec := range specs {
+		ref, countStr, found := strings.Cut(spec, ":")
+		if !found {
+			return nil, fmt.Errorf("malformed binaries entry `%v`: expected `name[=version]:count`", spec)
+		}
+
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("malformed binaries entry `%v`: count must be a positive integer", spec)
+		}
+
+		name, version, _ := strings.Cut(ref, "=")
+		binary, err := bao.LoadBinary(name)
+		if err != nil {
+			return nil, fmt.Errorf("unknown binary `%v` in binaries entry `%v`; register it with `devbao binary add`: %w", name, spec, err)
+		}
+
+		if version != "" && strings.TrimPrefix(version, "v") != strings.TrimPrefix(binary.Version, "v") {
+			return nil, fmt.Errorf("binary %v is version %v, not %v", name, binary.Version, version)
+		}
+
+		for i := 0; i < count; i++ {
+			binaries = append(binaries, name)
+		}
+	}
 
+	return binaries, nil
+}
+
+// haClusterMember describes one node of an HA cluster before it is built.
+type haClusterMember struct {
+	Name     string
+	NonVoter bool
+	Opts     []bao.NodeConfigOpt
+}
+
+// haClusterMembers lays out the names, listeners, and raft storage of the
+// nodes of an HA cluster; it is shared by `cluster start` and `up`, which
+// compares existing clusters against it.
+func haClusterMembers(clusterName string, listen string, portBase int, count int, nonVoter int, retryJoin bool, seals []bao.Seal) ([]*haClusterMember, error) {
+	var names []string
+	var listeners []*bao.TCPListener
+	var peers []*bao.RaftRetryJoin
+	for index := 0; index < count; index++ {
 		nvSuffix := ""
-		if nonVoter {
+		if index >= (count - nonVoter) {
 			nvSuffix = "-nv"
 		}
 
 		name := fmt.Sprintf("%v-node-%d%v", clusterName, index, nvSuffix)
-		fmt.Printf("starting %v...\n", name)
+		listener := &bao.TCPListener{
+			Address: fmt.Sprintf("%v:%d", listen, portBase+index*100),
+		}
 
-		port := portBase + index*100
+		// Peers are reached the same way as the members of existing
+		// clusters, with the scheme and CA of their listener.
+		probe := &bao.Node{Name: name}
+		if err := probe.Config.ApplyOpts(&bao.RaftStorage{}, listener); err != nil {
+			return nil, err
+		}
 
-		var opts []bao.NodeConfigOpt
+		peer, err := probe.RetryJoinPeer()
+		if err != nil {
+			return nil, err
+		}
 
-		opts = append(opts, &bao.RaftStorage{})
-		opts = append(opts, &bao.TCPListener{
-			Address: fmt.Sprintf("%v:%d", listen, port),
-		})
+		names = append(names, name)
+		listeners = append(listeners, listener)
+		peers = append(peers, peer)
+	}
 
-		seals := cCtx.StringSlice("seals")
-		for index, seal := range seals {
-			url, err := url.Parse(seal)
-			if err != nil {
-				return fmt.Errorf("failed parsing seal's uri at index %d (`%v`): %w", index, seal, err)
-			}
+	var members []*haClusterMember
+	for index, name := range names {
+		nonVoter := index >= (count - nonVoter)
 
-			// Assume transit.
+		var opts []bao.NodeConfigOpt
 
-			if url.User == nil || url.User.Username() == "" {
-				return fmt.Errorf("malformed or missing user info: expected token in username for Transit: `%v`", url.User.String())
+		// Fixing the raft ID lets the node be found when removing it from
+		// the cluster later.
+		storage := &bao.RaftStorage{NodeID: name}
+		if retryJoin {
+			for peerIndex, peer := range peers {
+				if peerIndex != index {
+					storage.RetryJoin = append(storage.RetryJoin, peer)
+				}
 			}
 
-			token := url.User.Username()
-			addr := fmt.Sprintf("%v://%v", url.Scheme, url.Host)
-
-			if !strings.Contains(url.Path, "/keys/") {
-				return fmt.Errorf("malformed path: no `/keys/` segment: `%v`", url.Path)
-			}
+			storage.RetryJoinAsNonVoter = nonVoter
+		}
 
-			parts := strings.Split(url.Path, "/keys/")
-			mount_path := strings.Join(parts[0:len(parts)-1], "/keys")
-			key_name := parts[len(parts)-1]
+		opts = append(opts, storage)
+		opts = append(opts, listeners[index])
 
-			opts = append(opts, &bao.TransitSeal{
-				Address:   addr,
-				Token:     token,
-				MountPath: mount_path,
-				KeyName:   key_name,
-			})
+		for _, seal := range seals {
+			opts = append(opts, seal)
 		}
 
+		members = append(members, &haClusterMember{
+			Name:     name,
+			NonVoter: nonVoter,
+			Opts:     opts,
+		})
+	}
+
+	return members, nil
+}
+
+// StartHACluster builds, starts, and joins count nodes into a new HA cluster,
+// the last nonVoter of which are non-voters, applying profiles to the leader.
+// With retryJoin, nodes join each other through their raft configuration.
+func StartHACluster(clusterName string, nType string, listen string, portBase int, count int, nonVoter int, retryJoin bool, faultProxy bool, seals []bao.Seal, binaries []string, profiles []string) (*bao.Cluster, error) {
+	members, err := haClusterMembers(clusterName, listen, portBase, count, nonVoter, retryJoin, seals)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build nodes
+	var nodes []*bao.Node
+	for index, member := range members {
+		name := member.Name
+		nonVoter := member.NonVoter
+		opts := member.Opts
+		fmt.Printf("starting %v...\n", name)
+
 		node, err := bao.BuildNode(name, nType, opts...)
 		if err != nil {
-			return fmt.Errorf("failed to build node %v: %w", name, err)
+			return nil, fmt.Errorf("failed to build node %v: %w", name, err)
 		}
 
 		node.NonVoter = nonVoter
-		if nonVoter {
+		node.Config.ClusterProxy = faultProxy
+		if index < len(binaries) {
+			node.Binary = binaries[index]
+		}
+
+		if nonVoter || faultProxy || node.Binary != "" {
 			if err := node.SaveConfig(); err != nil {
-				return fmt.Errorf("failed to save non-voter config for node %v: %w", name, err)
+				return nil, fmt.Errorf("failed to save config for node %v: %w", name, err)
 			}
 		}
 
 		if err := node.Start(); err != nil {
-			return fmt.Errorf("failed to start node %v: %w", name, err)
+			return nil, fmt.Errorf("failed to start node %v: %w", name, err)
 		}
 
 		if index == 0 {
 			// Only initialize the first node; otherwise, additional nodes will
 			// not join the cluster.
 			if err := node.Initialize(); err != nil {
-				return fmt.Errorf("failed to initialize node %v: %w", name, err)
+				return nil, fmt.Errorf("failed to initialize node %v: %w", name, err)
 			}
 
 			if _, err := node.Unseal(); err != nil {
-				return fmt.Errorf("failed to unseal node %v: %w", name, err)
+				return nil, fmt.Errorf("failed to unseal node %v: %w", name, err)
 			}
 		}
 
@@ -210,49 +329,92 @@ func RunClusterStartCommand(cCtx *cli.Context) error {
 	// Build initial cluster.
 	cluster, err := bao.BuildHACluster(clusterName, nodes[0].Name)
 	if err != nil {
-		return fmt.Errorf("failed to build cluster: %w", err)
+		return nil, fmt.Errorf("failed to build cluster: %w", err)
+	}
+
+	if retryJoin {
+		cluster.RetryJoin = true
+		if err := cluster.SaveConfig(); err != nil {
+			return nil, fmt.Errorf("failed to save cluster: %w", err)
+		}
+	}
+
+	if faultProxy {
+		fmt.Printf("starting fault-injection proxy...\n")
+		if err := cluster.StartProxy(); err != nil {
+			return nil, err
+		}
 	}
 
 	for _, node := range nodes[1:] {
 		// Give time for nodes to join...
 		time.Sleep(250 * time.Millisecond)
 
+		if retryJoin {
+			if err := registerRetryJoinNode(cluster, nodes[0], node); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
 		fmt.Printf("joining %v to cluster...\n", node.Name)
 
 		if err := cluster.JoinNodeHACluster(node); err != nil {
-			return fmt.Errorf("failed to join node %v to cluster: %w", node.Name, err)
+			return nil, fmt.Errorf("failed to join node %v to cluster: %w", node.Name, err)
 		}
 	}
 
 	// Give time for cluster to stabilize
-	leaderNode, leaderClient, err := cluster.GetLeader()
-	errCount := 0
-	for err != nil {
-		errCount += 1
-		if errCount > 5 {
-			return fmt.Errorf("failed to find cluster leader: %w", err)
-		}
-
-		time.Sleep(time.Duration(errCount) * time.Second)
-		leaderNode, leaderClient, err = cluster.GetLeader()
+	leaderNode, leaderClient, err := cluster.WaitForLeader()
+	if err != nil {
+		return nil, err
 	}
 
 	fmt.Printf("%v selected as leader\n", leaderNode.Name)
 
-	profiles := cCtx.StringSlice("profiles")
-	for profileIndex, profile := range profiles {
-		warnings, err := bao.ProfileSetup(leaderClient, profile)
-		if len(warnings) != 0 || err != nil {
-			fmt.Fprintf(os.Stderr, "for profile [%d/%v]:\n", profileIndex, profile)
-		}
+	if err := ApplyProfiles(leaderClient, profiles); err != nil {
+		return nil, err
+	}
 
-		for index, warning := range warnings {
-			fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, warning)
+	if len(profiles) > 0 {
+		cluster.Profiles = profiles
+		if err := cluster.SaveConfig(); err != nil {
+			return nil, fmt.Errorf("failed to save cluster: %w", err)
 		}
+	}
 
-		if err != nil {
-			return err
+	return cluster, nil
+}
+
+// registerRetryJoinNode records a node which joins the cluster on its own
+// through retry_join, sharing the leader's credentials and unsealing it with
+// them when Shamir's seal is in use.
+func registerRetryJoinNode(cluster *bao.Cluster, leader *bao.Node, node *bao.Node) error {
+	fmt.Printf("waiting for %v to join cluster...\n", node.Name)
+
+	node.Token = leader.Token
+	node.UnsealKeys = leader.UnsealKeys
+	if err := cluster.RegisterNodeHACluster(node); err != nil {
+		return fmt.Errorf("failed to add node %v to cluster: %w", node.Name, err)
+	}
+
+	if len(node.Config.Seals) > 0 {
+		return nil
+	}
+
+	// The node only joins once it has been given the unseal keys; it may
+	// take several attempts before the leader is reachable.
+	_, err := node.Unseal()
+	errCount := 0
+	for err != nil {
+		errCount += 1
+		if errCount > 5 {
+			return fmt.Errorf("failed unsealing follower node %v: %w", node.Name, err)
 		}
+
+		time.Sleep(time.Duration(errCount) * time.Second)
+		_, err = node.Unseal()
 	}
 
 	return nil
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterStatusCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "status",
+		Aliases:   []string{"st"},
+		ArgsUsage: "<name>",
+		Usage:     "show process, seal, and raft state of each cluster member",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "table",
+				Usage: "output format: `table` or `json`",
+			},
+			&cli.BoolFlag{
+				Name:    "watch",
+				Aliases: []string{"w"},
+				Usage:   "continuously refresh the status until interrupted",
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Value: 2 * time.Second,
+				Usage: "time between refreshes with --watch",
+			},
+		},
+
+		Action: RunClusterStatusCommand,
+	}
+
+	return c
+}
+
+func RunClusterStatusCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the name of the cluster")
+	}
+
+	format := cCtx.String("format")
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown value for --format: valid values are `table` and `json`; got `%v`", format)
+	}
+
+	interval := cCtx.Duration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive; got %v", interval)
+	}
+
+	clusterName := cCtx.Args().First()
+	for {
+		cluster, err := bao.LoadCluster(clusterName)
+		if err != nil {
+			return fmt.Errorf("error loading cluster: %w", err)
+		}
+
+		status, err := cluster.Status()
+		if err != nil {
+			return err
+		}
+
+		if cCtx.Bool("watch") && format == "table" {
+			// Clear the screen between refreshes.
+			fmt.Print("\033[H\033[2J")
+		}
+
+		if err := PrintClusterStatus(status, format); err != nil {
+			return err
+		}
+
+		if !cCtx.Bool("watch") {
+			return nil
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func PrintClusterStatus(status *bao.ClusterStatus, format string) error {
+	if format == "json" {
+		return json.NewEncoder(os.Stdout).Encode(status)
+	}
+
+	leader := status.Leader
+	if leader == "" {
+		leader = "(none)"
+	}
+
+	fmt.Printf("cluster %v: leader %v, healthy %v, failure tolerance %v\n\n", status.Name, leader, status.Healthy, status.FailureTolerance)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tPROCESS\tSEAL\tHA STATE\tRAFT ID\tVOTER\tLAST INDEX\tLAST TERM\tHEALTHY\tBINARY\tVERSION")
+	for _, member := range status.Members {
+		process := "stopped"
+		if member.Running {
+			process = fmt.Sprintf("running (%v)", member.Pid)
+		}
+
+		seal := "-"
+		if member.Running {
+			switch {
+			case !member.Initialized:
+				seal = "uninitialized"
+			case member.Sealed:
+				seal = "sealed"
+			default:
+				seal = "unsealed"
+			}
+		}
+
+		nodeId := member.NodeID
+		if nodeId == "" {
+			nodeId = "-"
+		}
+
+		binary := member.Binary
+		if binary == "" {
+			binary = "-"
+		}
+
+		version := member.Version
+		if version == "" {
+			version = "-"
+		}
+
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", member.Name, process, seal, member.HAState, nodeId, member.Voter, member.LastIndex, member.LastTerm, member.Healthy, binary, version)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var errors []string
+	errors = append(errors, status.Errors...)
+	for _, member := range status.Members {
+		for _, err := range member.Errors {
+			errors = append(errors, fmt.Sprintf("%v: %v", member.Name, err))
+		}
+	}
+
+	for index, err := range errors {
+		fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, err)
+	}
+
+	return nil
+}
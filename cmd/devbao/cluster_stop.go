@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterStopCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "stop",
+		Aliases:   []string{"k"},
+		ArgsUsage: "<name>",
+		Usage:     "stop all running nodes of the cluster",
+
+		Action: RunClusterStopCommand,
+	}
+
+	return c
+}
+
+func RunClusterStopCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	voters, nonVoters, err := cluster.LoadMembers()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("stopping cluster %v...\n", cluster.Name)
+	results := bao.ForEachNode(append(voters, nonVoters...), func(node *bao.Node) (string, error) {
+		if node.Exec == nil || node.Exec.ValidateRunning() != nil {
+			return "already stopped", nil
+		}
+
+		pid := node.Exec.Pid
+		if err := node.Kill(); err != nil {
+			return "", err
+		}
+
+		if err := node.Exec.WaitStopped(); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("stopped pid %v", pid), nil
+	})
+
+	if err := PrintClusterNodeResults(results); err != nil {
+		return err
+	}
+
+	if cluster.ProxyRunning() {
+		fmt.Printf("stopping fault-injection proxy...\n")
+		return cluster.StopProxy()
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterUnsealCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "unseal",
+		Aliases:   []string{"u"},
+		ArgsUsage: "<name>",
+		Usage:     "unseal all running nodes of the cluster using locally stored unseal keys",
+
+		Action: RunClusterUnsealCommand,
+	}
+
+	return c
+}
+
+func unsealClusterNode(node *bao.Node) (string, error) {
+	if node.Exec == nil || node.Exec.ValidateRunning() != nil {
+		return "", fmt.Errorf("node is not running")
+	}
+
+	unsealed, err := node.Unseal()
+	if err != nil {
+		return "", err
+	}
+
+	if !unsealed {
+		return "already unsealed", nil
+	}
+
+	return "unsealed", nil
+}
+
+func RunClusterUnsealCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	voters, nonVoters, err := cluster.LoadMembers()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("unsealing cluster %v...\n", cluster.Name)
+
+	// Voters are unsealed first so that a leader can be elected before
+	// non-voters attempt to contact it.
+	results := bao.ForEachNode(voters, unsealClusterNode)
+	if err := PrintClusterNodeResults(results); err != nil {
+		return err
+	}
+
+	leaderNode, _, err := cluster.WaitForLeader()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v selected as leader\n", leaderNode.Name)
+
+	results = bao.ForEachNode(nonVoters, unsealClusterNode)
+	return PrintClusterNodeResults(results)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildClusterUpgradeCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "upgrade",
+		ArgsUsage: "<name>",
+		Usage:     "pin each member to a new binary and restart it, one at a time, waiting for autopilot health in between",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "binary",
+				Required: true,
+				Usage:    "name of a registered binary, or path to the server binary, to upgrade to",
+			},
+		},
+
+		Action: RunClusterUpgradeCommand,
+	}
+
+	c.Flags = append(c.Flags, RollingFlags()...)
+
+	return c
+}
+
+func RunClusterUpgradeCommand(cCtx *cli.Context) error {
+	cluster, err := LoadClusterArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	binary, err := bao.PinBinaryRef(cCtx.String("binary"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("upgrading cluster %v to %v...\n", cluster.Name, binary)
+
+	return RollCluster(cCtx, cluster, func(node *bao.Node) error {
+		node.PinBinary(binary)
+		if err := node.SaveConfig(); err != nil {
+			return fmt.Errorf("failed to pin binary of node %v: %w", node.Name, err)
+		}
+
+		return nil
+	})
+}
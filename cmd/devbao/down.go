@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildDownCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "down",
+		ArgsUsage: "[spec]",
+		Usage:     "remove the nodes and clusters described by a spec file",
+		Description: "Tears down the spec (default: devbao.hcl) in reverse dependency order. With --stop,\n" +
+			"processes are stopped but their state is kept for a later `devbao up`.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "stop",
+				Usage: "only stop nodes, keeping their data",
+			},
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "continue removal despite errors",
+			},
+		},
+
+		Action: RunDownCommand,
+	}
+
+	c.Flags = append(c.Flags, SpecFlags()...)
+
+	return c
+}
+
+func RunDownCommand(cCtx *cli.Context) error {
+	spec, err := LoadSpecArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	order, err := spec.Order()
+	if err != nil {
+		return err
+	}
+
+	stop := cCtx.Bool("stop")
+	force := cCtx.Bool("force")
+	dryRun := cCtx.Bool("dry-run")
+
+	for index := len(order) - 1; index >= 0; index-- {
+		resource := order[index]
+
+		switch tRes := resource.(type) {
+		case *bao.SpecNode:
+			err = DownNode(tRes.Name, stop, force, dryRun)
+		case *bao.SpecCluster:
+			err = DownCluster(tRes.Name, stop, force, dryRun)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to bring down %v: %w", resource.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+func DownNode(name string, stop bool, force bool, dryRun bool) error {
+	node, err := bao.LoadNodeUnvalidated(name)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such file or directory") {
+			fmt.Fprintf(os.Stderr, "node %v was already removed\n", name)
+			return nil
+		}
+
+		if !force {
+			return fmt.Errorf("failed to load node to determine state: %w", err)
+		}
+
+		node = &bao.Node{
+			Name: name,
+		}
+	}
+
+	if dryRun {
+		if stop {
+			fmt.Printf("would stop node %v\n", name)
+		} else {
+			fmt.Printf("would remove node %v\n", name)
+		}
+
+		return nil
+	}
+
+	if node.Exec != nil {
+		if err := node.Exec.ValidateRunning(); err == nil {
+			fmt.Printf("stopping node %v...\n", name)
+			if err := node.Kill(); err != nil && !force {
+				return fmt.Errorf("failed to stop node: %w", err)
+			}
+		}
+	}
+
+	if stop {
+		return nil
+	}
+
+	fmt.Printf("removing node %v...\n", name)
+	return node.Clean(force)
+}
+
+func DownCluster(name string, stop bool, force bool, dryRun bool) error {
+	cluster, err := bao.LoadClusterUnvalidated(name)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such file or directory") {
+			fmt.Fprintf(os.Stderr, "cluster %v was already removed\n", name)
+			return nil
+		}
+
+		if !force {
+			return fmt.Errorf("failed to load cluster to determine state: %w", err)
+		}
+
+		cluster = &bao.Cluster{
+			Name: name,
+		}
+	}
+
+	if dryRun {
+		if stop {
+			fmt.Printf("would stop cluster %v\n", name)
+		} else {
+			fmt.Printf("would remove cluster %v\n", name)
+		}
+
+		return nil
+	}
+
+	if !stop {
+		fmt.Printf("cleaning cluster %v...\n", name)
+		return cluster.Clean(force)
+	}
+
+	for _, nodeName := range cluster.Nodes {
+		if err := DownNode(nodeName, true, force, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -17,10 +17,13 @@ func main() {
 		Suggest:                true,
 	}
 
+	app.Commands = append(app.Commands, BuildBinaryCommand())
 	app.Commands = append(app.Commands, BuildClusterCommand())
 	app.Commands = append(app.Commands, BuildNodeCommand())
 	app.Commands = append(app.Commands, BuildProfileCommand())
 	app.Commands = append(app.Commands, BuildTUICommand())
+	app.Commands = append(app.Commands, BuildUpCommand())
+	app.Commands = append(app.Commands, BuildDownCommand())
 
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -11,24 +11,39 @@ func BuildNodeCommand() *cli.Command {
 		Usage:   "commands for managing individual nodes",
 	}
 
+	c.Subcommands = append(c.Subcommands, BuildNodeAdoptCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeCheckpointCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeCleanCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeCloneCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeConfigCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeCopyCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeDiffCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeDirCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeEnvCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeExportCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeExportTerraformCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeGetTokenCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeGetUnsealCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeImportCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeInitializeCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeListCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeMigrateStorageCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodePackCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeRebuildCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeResumeCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeSealCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeSetAddressCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeSetBinaryCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeSetTokenCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeSetUnsealCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeSnapshotCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeStartCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeStartDevCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeStopCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeTailCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeTailAuditCommand())
 	c.Subcommands = append(c.Subcommands, BuildNodeUnsealCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeUnpackCommand())
 
 	return c
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeAdoptCommand() *cli.Command {
+	c := &cli.Command{
+		Name:  "adopt",
+		Usage: "register running servers not launched by devbao as nodes",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "pid",
+				Value: 0,
+				Usage: "only adopt the server with this process id",
+			},
+			&cli.StringFlag{
+				Name:  "name",
+				Value: "",
+				Usage: "name for the adopted node; requires --pid. Defaults to `adopted-<pid>`",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Value: false,
+				Usage: "only list servers which would be adopted",
+			},
+		},
+
+		Action: RunNodeAdoptCommand,
+	}
+
+	return c
+}
+
+func RunNodeAdoptCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Present() {
+		return fmt.Errorf("unexpected positional argument -- this command takes none: `%v`", cCtx.Args().First())
+	}
+
+	pid := cCtx.Int("pid")
+	name := cCtx.String("name")
+	dryRun := cCtx.Bool("dry-run")
+
+	if name != "" && pid == 0 {
+		return fmt.Errorf("--name requires --pid, but was not provided")
+	}
+
+	candidates, err := bao.FindUntrackedServers()
+	if err != nil {
+		return fmt.Errorf("failed to scan for running servers: %w", err)
+	}
+
+	found := false
+	for _, candidate := range candidates {
+		if pid != 0 && candidate.Pid != pid {
+			continue
+		}
+
+		found = true
+
+		nodeName := name
+		if nodeName == "" {
+			nodeName = fmt.Sprintf("adopted-%d", candidate.Pid)
+		}
+
+		fmt.Printf(" - pid %v: %v %v\n", candidate.Pid, candidate.Binary, strings.Join(candidate.Args, " "))
+		if dryRun {
+			continue
+		}
+
+		present, err := bao.NodeExists(nodeName)
+		if err != nil {
+			return fmt.Errorf("error checking if node exists: %w", err)
+		}
+
+		if present {
+			return fmt.Errorf("refusing to overwrite existing node %v", nodeName)
+		}
+
+		node, warnings, err := bao.AdoptNode(nodeName, candidate)
+		for index, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "   - [warning %d]: %v\n", index, warning)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to adopt process %d: %w", candidate.Pid, err)
+		}
+
+		fmt.Printf("   adopted as node %v (%v)\n", node.Name, node.Exec.ConnectAddress)
+	}
+
+	if !found {
+		if pid != 0 {
+			return fmt.Errorf("no untracked server found with pid %d", pid)
+		}
+
+		fmt.Fprintf(os.Stderr, "no untracked servers found\n")
+	}
+
+	return nil
+}
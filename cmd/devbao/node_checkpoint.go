@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeCheckpointCommand() *cli.Command {
+	c := &cli.Command{
+		Name:    "checkpoint",
+		Aliases: []string{"cp"},
+		Usage:   "commands for archiving and restoring a stopped node's storage on disk",
+	}
+
+	c.Subcommands = append(c.Subcommands, BuildNodeCheckpointCreateCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeCheckpointListCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeCheckpointRestoreCommand())
+
+	return c
+}
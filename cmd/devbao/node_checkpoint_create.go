@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeCheckpointCreateCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "create",
+		ArgsUsage: "<name> [checkpoint]",
+		Usage:     "stop the node and archive its storage and configuration, resuming it afterwards; the checkpoint is named after the current time by default",
+
+		Action: RunNodeCheckpointCreateCommand,
+	}
+
+	return c
+}
+
+func RunNodeCheckpointCreateCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to checkpoint")
+	}
+
+	node, err := bao.LoadNode(cCtx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	checkpoint, err := node.CreateCheckpoint(cCtx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created checkpoint %v of node %v (%v storage, %v bytes)\n", checkpoint.Name, node.Name, checkpoint.Storage, checkpoint.Size)
+
+	return nil
+}
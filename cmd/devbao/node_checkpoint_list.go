@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeCheckpointListCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "list",
+		Aliases:   []string{"l"},
+		ArgsUsage: "<name>",
+		Usage:     "list the node's checkpoints, oldest first",
+
+		Action: RunNodeCheckpointListCommand,
+	}
+
+	return c
+}
+
+func RunNodeCheckpointListCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to list checkpoints of")
+	}
+
+	node, err := bao.LoadNode(cCtx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	checkpoints, err := node.ListCheckpoints()
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, checkpoint := range checkpoints {
+		lines = append(lines, fmt.Sprintf(" - %v: %v [storage: %v] [size: %v]", checkpoint.Name, checkpoint.Time.Local().Format("2006-01-02 15:04:05"), checkpoint.Storage, checkpoint.Size))
+	}
+
+	fmt.Println(strings.Join(lines, "\n"))
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeCheckpointRestoreCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "restore",
+		ArgsUsage: "<name> [checkpoint]",
+		Usage:     "stop the node and replace its storage and configuration with a checkpoint's, by default the most recent, resuming it afterwards",
+
+		Action: RunNodeCheckpointRestoreCommand,
+	}
+
+	return c
+}
+
+func RunNodeCheckpointRestoreCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to restore")
+	}
+
+	node, err := bao.LoadNode(cCtx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	name := cCtx.Args().Get(1)
+	if name == "" {
+		checkpoints, err := node.ListCheckpoints()
+		if err != nil {
+			return err
+		}
+
+		if len(checkpoints) == 0 {
+			return fmt.Errorf("node %v has no checkpoints to restore", node.Name)
+		}
+
+		name = checkpoints[len(checkpoints)-1].Name
+	}
+
+	if node.Cluster != "" {
+		fmt.Fprintf(os.Stderr, "[warning] node %v is a member of cluster %v; restoring its raft storage alone may conflict with the other members\n", node.Name, node.Cluster)
+	}
+
+	fmt.Printf("restoring checkpoint %v of node %v...\n", name, node.Name)
+
+	return node.RestoreCheckpoint(name)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeCloneCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "clone",
+		ArgsUsage: "<src> <dst>",
+		Usage:     "copy a node, including its storage, keys, and token, into a new independent node on free ports",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "resume",
+				Usage: "start (and unseal) the clone once created",
+			},
+		},
+
+		Action: RunNodeCloneCommand,
+	}
+
+	return c
+}
+
+func RunNodeCloneCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 2 {
+		return fmt.Errorf("missing required positional arguments:\n\t<src>, the node to clone\n\t<dst>, the name of the new node")
+	}
+
+	src, err := bao.LoadNode(cCtx.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	clone, err := src.Clone(cCtx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	addr, _, err := clone.GetConnectAddr()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("cloned node %v into %v at %v\n", src.Name, clone.Name, addr)
+
+	if !cCtx.Bool("resume") {
+		return nil
+	}
+
+	_, err = EnsureRunning(clone)
+	return err
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+	"github.com/openbao/devbao/pkg/utils"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeConfigCommand() *cli.Command {
+	c := &cli.Command{
+		Name:    "config",
+		Aliases: []string{"cfg"},
+		Usage:   "commands for viewing and editing an existing node's configuration",
+	}
+
+	c.Subcommands = append(c.Subcommands, BuildNodeConfigAddListenerCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeConfigRemoveListenerCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeConfigSetCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeConfigSetLogLevelCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeConfigSetRaftCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeConfigSetUICommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeConfigShowCommand())
+
+	return c
+}
+
+func NodeConfigFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Value: false,
+			Usage: "only show the resulting configuration diff without applying it",
+		},
+	}
+}
+
+// ApplyNodeConfigChange loads the named node, applies the mutation to its
+// configuration, and shows the resulting diff. Unless in dry-run mode, the
+// new configuration is then persisted and a running node is either reloaded
+// (for reloadable changes such as the log level) or restarted and unsealed.
+func ApplyNodeConfigChange(cCtx *cli.Context, name string, mutate func(node *bao.Node) error) error {
+	dryRun := cCtx.Bool("dry-run")
+
+	current, err := bao.LoadNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	if current.Config.Dev != nil {
+		return fmt.Errorf("node %v is a dev mode instance; its configuration cannot be edited", name)
+	}
+
+	// Adopted nodes keep running from their original -config arguments,
+	// so a regenerated config.hcl would never be read.
+	if current.Adopted {
+		return fmt.Errorf("node %v was adopted; its configuration is managed outside of devbao and cannot be edited", name)
+	}
+
+	updated, err := bao.LoadNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	if err := mutate(updated); err != nil {
+		return err
+	}
+
+	if err := updated.Config.Validate(); err != nil {
+		return fmt.Errorf("updated configuration is not valid: %w", err)
+	}
+
+	before, err := current.PreviewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to render current configuration: %w", err)
+	}
+
+	after, err := updated.PreviewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to render updated configuration: %w", err)
+	}
+
+	diff := utils.LineDiff(before, after)
+
+	currentJson, err := json.Marshal(current.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal current configuration: %w", err)
+	}
+
+	updatedJson, err := json.Marshal(updated.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated configuration: %w", err)
+	}
+
+	if diff == "" && string(currentJson) == string(updatedJson) {
+		fmt.Fprintf(os.Stderr, "no changes to node %v's configuration\n", name)
+		return nil
+	}
+
+	if diff != "" {
+		fmt.Printf("# ===== %v changes for node %v ===== #\n\n%v\n", bao.InstanceConfigName, name, diff)
+	}
+
+	auditsChanged := !reflect.DeepEqual(current.Config.Audits, updated.Config.Audits)
+	if auditsChanged {
+		fmt.Printf("audit devices changed: %d -> %d\n", len(current.Config.Audits), len(updated.Config.Audits))
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	if err := updated.SaveConfig(); err != nil {
+		return fmt.Errorf("failed to save node configuration: %w", err)
+	}
+
+	// Render again for real, persisting TLS material and creating storage
+	// directories.
+	rendered, err := updated.RenderConfig()
+	if err != nil {
+		return fmt.Errorf("failed to render updated configuration: %w", err)
+	}
+
+	if _, err := updated.SaveInstanceConfig(rendered); err != nil {
+		return fmt.Errorf("failed to save instance configuration: %w", err)
+	}
+
+	// Audit devices are enabled through the API, so those removed from the
+	// configuration stay enabled until they are disabled as well.
+	var removedAudits []bao.Audit
+	for _, audit := range current.Config.Audits {
+		kept := false
+		for _, other := range updated.Config.Audits {
+			if other.DeviceName() == audit.DeviceName() {
+				kept = true
+				break
+			}
+		}
+
+		if !kept {
+			removedAudits = append(removedAudits, audit)
+		}
+	}
+
+	if current.Exec == nil || current.Exec.ValidateRunning() != nil {
+		fmt.Printf("node %v is not running; changes will apply on next resume\n", name)
+		for _, audit := range removedAudits {
+			fmt.Fprintf(os.Stderr, "[warning] audit device %v stays enabled; disable it with `bao audit disable %v` once the node is running\n", audit.DeviceName(), audit.DeviceName())
+		}
+
+		return nil
+	}
+
+	if diff != "" {
+		reload := true
+		for _, line := range utils.ChangedLines(diff) {
+			if !strings.HasPrefix(line, "log_level") {
+				reload = false
+				break
+			}
+		}
+
+		if reload {
+			fmt.Printf("reloading node %v / pid %v...\n", name, updated.Exec.Pid)
+			if err := updated.Reload(); err != nil {
+				return fmt.Errorf("failed to reload node: %w", err)
+			}
+		} else {
+			fmt.Printf("restarting node %v / pid %v...\n", name, updated.Exec.Pid)
+			if err := updated.Restart(); err != nil {
+				return fmt.Errorf("failed to restart node: %w", err)
+			}
+		}
+	}
+
+	if auditsChanged {
+		if err := updated.PostInitializeUnseal(); err != nil {
+			return fmt.Errorf("failed to apply post-unseal initialization; %w", err)
+		}
+
+		if err := updated.DisableAudits(removedAudits); err != nil {
+			return fmt.Errorf("failed to disable removed audit devices: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeConfigAddListenerCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "add-listener",
+		ArgsUsage: "<name> <listener>",
+		Usage:     "add a listener to the node; use `tcp:<bind address>` or `unix:<path>`",
+
+		Action: RunNodeConfigAddListenerCommand,
+	}
+
+	c.Flags = append(c.Flags, NodeConfigFlags()...)
+
+	return c
+}
+
+func RunNodeConfigAddListenerCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 2 {
+		return fmt.Errorf("missing required positional argument:\n\t<name>, the node to add a listener to\n\t<listener>, the listener to add")
+	}
+
+	name := cCtx.Args().First()
+	listener, err := ParseListener(cCtx.Args().Get(1))
+	if err != nil {
+		return fmt.Errorf("failed parsing listener: %w", err)
+	}
+
+	return ApplyNodeConfigChange(cCtx, name, func(node *bao.Node) error {
+		node.Config.Listeners = append(node.Config.Listeners, listener)
+		return nil
+	})
+}
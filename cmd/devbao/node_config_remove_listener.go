@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeConfigRemoveListenerCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "remove-listener",
+		ArgsUsage: "<name> <listener>",
+		Usage:     "remove a listener from the node, by index, `tcp:<bind address>`, or `unix:<path>`",
+
+		Action: RunNodeConfigRemoveListenerCommand,
+	}
+
+	c.Flags = append(c.Flags, NodeConfigFlags()...)
+
+	return c
+}
+
+func RunNodeConfigRemoveListenerCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 2 {
+		return fmt.Errorf("missing required positional argument:\n\t<name>, the node to remove a listener from\n\t<listener>, the index or address of the listener to remove")
+	}
+
+	name := cCtx.Args().First()
+	ref := cCtx.Args().Get(1)
+
+	return ApplyNodeConfigChange(cCtx, name, func(node *bao.Node) error {
+		listenerIndex := -1
+		if index, err := strconv.Atoi(ref); err == nil {
+			listenerIndex = index
+		} else {
+			for index, listener := range node.Config.Listeners {
+				switch tListener := listener.(type) {
+				case *bao.TCPListener:
+					if ref == "tcp:"+tListener.Address {
+						listenerIndex = index
+					}
+				case *bao.UnixListener:
+					if ref == "unix:"+tListener.Path {
+						listenerIndex = index
+					}
+				}
+			}
+		}
+
+		if listenerIndex < 0 || listenerIndex >= len(node.Config.Listeners) {
+			return fmt.Errorf("no listener matching `%v` on node %v", ref, name)
+		}
+
+		before := node.Config.Listeners[0:listenerIndex]
+		after := node.Config.Listeners[listenerIndex+1:]
+		node.Config.Listeners = append(before, after...)
+
+		return nil
+	})
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeConfigSetCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "set",
+		ArgsUsage: "<name>",
+		Usage:     "update the storage, seals, or audit devices of a node",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "storage",
+				Usage: "Storage backend to use; choose between `raft`, `file`, or `inmem`. Changing storage discards existing data; see `node migrate-storage` to keep it.",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "allow changing the storage of an initialized node, discarding its data, token, and unseal keys and re-initializing it",
+			},
+			&cli.StringSliceFlag{
+				Name:  "seals",
+				Usage: "URI schemes of seals to replace existing seals with; can be specified multiple times. Use\n\t`http(s)://<TOKEN>@<ADDR>/<MOUNT_PATH>/keys/<KEY_NAME>` for Transit.",
+			},
+			&cli.BoolFlag{
+				Name:  "clear-seals",
+				Usage: "remove all seals from the node",
+			},
+			&cli.BoolFlag{
+				Name:  "audit",
+				Usage: "enable or disable file auditing of requests",
+			},
+		},
+
+		Action: RunNodeConfigSetCommand,
+	}
+
+	c.Flags = append(c.Flags, NodeConfigFlags()...)
+
+	return c
+}
+
+func RunNodeConfigSetCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node whose configuration should be updated")
+	}
+
+	name := cCtx.Args().First()
+
+	if cCtx.IsSet("seals") && cCtx.Bool("clear-seals") {
+		return fmt.Errorf("--seals and --clear-seals are mutually exclusive")
+	}
+
+	reinitialize := false
+	err := ApplyNodeConfigChange(cCtx, name, func(node *bao.Node) error {
+		if cCtx.IsSet("storage") {
+			storage := cCtx.String("storage")
+			switch storage {
+			case "raft", "file", "inmem":
+			default:
+				return fmt.Errorf("unknown value for -storage: `%v`; supported values are `raft`, `file`, or `inmem`", storage)
+			}
+
+			// Keep the existing storage, with its node ID, retry_join
+			// peers, path, and tuning, when its type is unchanged.
+			if node.Config.StorageType != storage {
+				switch storage {
+				case "raft":
+					node.Config.Storage = &bao.RaftStorage{}
+				case "file":
+					node.Config.Storage = &bao.FileStorage{}
+				case "inmem":
+					node.Config.Storage = &bao.InmemStorage{}
+				}
+
+				// The token and unseal keys belong to the old storage's
+				// barrier and cannot be used with the new storage.
+				if node.Token != "" || len(node.UnsealKeys) > 0 {
+					if !cCtx.Bool("force") {
+						return fmt.Errorf("node %v is initialized; changing its storage from %v to %v would discard its data, token, and unseal keys\n\tuse `devbao node migrate-storage --to %v %v` to keep its data, or pass --force to discard it and re-initialize the node", name, node.Config.StorageType, storage, storage, name)
+					}
+
+					node.Token = ""
+					node.UnsealKeys = nil
+					reinitialize = true
+				}
+
+				fmt.Fprintf(os.Stderr, "[warning] changing storage from %v to %v; existing data will not be migrated\n", node.Config.StorageType, storage)
+			}
+		}
+
+		if cCtx.Bool("clear-seals") {
+			node.Config.Seals = nil
+		}
+
+		if cCtx.IsSet("seals") {
+			node.Config.Seals = nil
+			for index, seal := range cCtx.StringSlice("seals") {
+				parsed, err := ParseSeal(seal)
+				if err != nil {
+					return fmt.Errorf("failed parsing seal's uri at index %d (`%v`): %w", index, seal, err)
+				}
+
+				node.Config.Seals = append(node.Config.Seals, parsed)
+			}
+		}
+
+		if cCtx.IsSet("audit") {
+			node.Config.Audits = nil
+			if cCtx.Bool("audit") {
+				node.Config.Audits = append(node.Config.Audits, &bao.FileAudit{})
+				node.Config.Audits = append(node.Config.Audits, &bao.FileAudit{
+					CommonAudit: bao.CommonAudit{
+						LogRaw: true,
+					},
+				})
+			}
+		}
+
+		return nil
+	})
+	if err != nil || !reinitialize || cCtx.Bool("dry-run") {
+		return err
+	}
+
+	node, err := bao.LoadNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	if node.Exec == nil || node.Exec.ValidateRunning() != nil {
+		fmt.Printf("node %v must be initialized on next resume: `devbao node initialize %v`\n", name, name)
+		return nil
+	}
+
+	fmt.Printf("re-initializing node %v...\n", name)
+	if err := node.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize node: %w", err)
+	}
+
+	if len(node.Config.Seals) == 0 {
+		if _, err := node.Unseal(); err != nil {
+			return fmt.Errorf("failed to unseal node: %w", err)
+		}
+
+		// TODO: use a client request with proper back-off to determine
+		// when the node is responding.
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	if err := node.PostInitializeUnseal(); err != nil {
+		return fmt.Errorf("failed to apply post-unseal initialization; %w", err)
+	}
+
+	return nil
+}
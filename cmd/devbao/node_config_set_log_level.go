@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeConfigSetLogLevelCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "set-log-level",
+		ArgsUsage: "<name> <level>",
+		Usage:     "set the server log level; one of `trace`, `debug`, `info`, `warn`, or `error`",
+
+		Action: RunNodeConfigSetLogLevelCommand,
+	}
+
+	c.Flags = append(c.Flags, NodeConfigFlags()...)
+
+	return c
+}
+
+func RunNodeConfigSetLogLevelCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 2 {
+		return fmt.Errorf("missing required positional argument:\n\t<name>, the node to update\n\t<level>, the new log level")
+	}
+
+	name := cCtx.Args().First()
+	level := cCtx.Args().Get(1)
+
+	return ApplyNodeConfigChange(cCtx, name, func(node *bao.Node) error {
+		node.Config.LogLevel = level
+		return nil
+	})
+}
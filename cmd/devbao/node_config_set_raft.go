@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeConfigSetRaftCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "set-raft",
+		ArgsUsage: "<name>",
+		Usage:     "tune the raft storage of a node; use 0 (or an empty node id) to restore the default",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "node-id",
+				Usage: "fixed raft server ID of the node",
+			},
+			&cli.IntFlag{
+				Name:  "performance-multiplier",
+				Usage: "scaling factor for raft timing; higher values tolerate slower hosts",
+			},
+			&cli.IntFlag{
+				Name:  "snapshot-threshold",
+				Usage: "number of commits between snapshots",
+			},
+			&cli.IntFlag{
+				Name:  "trailing-logs",
+				Usage: "number of log entries kept after a snapshot",
+			},
+			&cli.IntFlag{
+				Name:  "max-entry-size",
+				Usage: "maximum size in bytes of a raft log entry",
+			},
+		},
+
+		Action: RunNodeConfigSetRaftCommand,
+	}
+
+	c.Flags = append(c.Flags, NodeConfigFlags()...)
+
+	return c
+}
+
+func RunNodeConfigSetRaftCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node whose raft storage should be tuned")
+	}
+
+	name := cCtx.Args().First()
+
+	return ApplyNodeConfigChange(cCtx, name, func(node *bao.Node) error {
+		storage, ok := node.Config.Storage.(*bao.RaftStorage)
+		if !ok {
+			return fmt.Errorf("node %v does not use raft storage", name)
+		}
+
+		if cCtx.IsSet("node-id") {
+			if node.Cluster != "" && storage.NodeID != cCtx.String("node-id") {
+				fmt.Fprintf(os.Stderr, "[warning] changing the node id of a clustered node; remove and rejoin it to the cluster %v\n", node.Cluster)
+			}
+
+			storage.NodeID = cCtx.String("node-id")
+		}
+
+		for flag, field := range map[string]*int{
+			"performance-multiplier": &storage.PerformanceMultiplier,
+			"snapshot-threshold":     &storage.SnapshotThreshold,
+			"trailing-logs":          &storage.TrailingLogs,
+			"max-entry-size":         &storage.MaxEntrySize,
+		} {
+			if !cCtx.IsSet(flag) {
+				continue
+			}
+
+			if cCtx.Int(flag) < 0 {
+				return fmt.Errorf("--%v must not be negative; got %v", flag, cCtx.Int(flag))
+			}
+
+			*field = cCtx.Int(flag)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeConfigSetUICommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "set-ui",
+		ArgsUsage: "<name> <true|false>",
+		Usage:     "enable or disable the web UI",
+
+		Action: RunNodeConfigSetUICommand,
+	}
+
+	c.Flags = append(c.Flags, NodeConfigFlags()...)
+
+	return c
+}
+
+func RunNodeConfigSetUICommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 2 {
+		return fmt.Errorf("missing required positional argument:\n\t<name>, the node to update\n\t<enabled>, whether to enable the web UI")
+	}
+
+	name := cCtx.Args().First()
+	enabled, err := strconv.ParseBool(cCtx.Args().Get(1))
+	if err != nil {
+		return fmt.Errorf("failed to parse <enabled> as a boolean: %w", err)
+	}
+
+	return ApplyNodeConfigChange(cCtx, name, func(node *bao.Node) error {
+		node.Config.UI = &bao.UI{Enabled: enabled}
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeConfigShowCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "show",
+		Aliases:   []string{"s"},
+		ArgsUsage: "<name>",
+		Usage:     "show the node's server configuration",
+
+		Action: RunNodeConfigShowCommand,
+	}
+
+	return c
+}
+
+func RunNodeConfigShowCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node whose configuration should be shown")
+	}
+
+	name := cCtx.Args().First()
+	node, err := bao.LoadNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	if node.Config.Dev != nil {
+		fmt.Printf("# node %v is a dev mode instance\n", name)
+		if node.Exec != nil {
+			fmt.Printf("# args: %v\n", node.Exec.Args)
+		}
+		return nil
+	}
+
+	path := filepath.Join(node.GetDirectory(), bao.InstanceConfigName)
+	config, err := os.ReadFile(path)
+	if err != nil {
+		rendered, err := node.PreviewConfig()
+		if err != nil {
+			return fmt.Errorf("failed to render node configuration: %w", err)
+		}
+
+		fmt.Fprintf(os.Stderr, "[warning] node %v has no saved %v; showing rendered configuration\n", name, bao.InstanceConfigName)
+		config = []byte(rendered)
+	}
+
+	fmt.Printf("# ===== %v ===== #\n\n%v", path, string(config))
+	return nil
+}
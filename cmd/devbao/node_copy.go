@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeCopyCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "copy",
+		ArgsUsage: "<src> <dst>",
+		Usage:     "copy KV data, policies, auth methods, and mounts from one running node to another",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "kv",
+				Usage: "KV path (mount or subtree) to copy recursively; may be repeated",
+			},
+			&cli.BoolFlag{
+				Name:  "policies",
+				Usage: "copy ACL policies",
+			},
+			&cli.BoolFlag{
+				Name:  "auth",
+				Usage: "copy auth methods, their tunables, and userpass users and approle roles",
+			},
+			&cli.BoolFlag{
+				Name:  "mounts",
+				Usage: "copy secret engine mounts and their tunables",
+			},
+			&cli.StringFlag{
+				Name:  "userpass-password",
+				Usage: "password for userpass users missing on the destination, as passwords cannot be copied",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Value: false,
+				Usage: "only show the diff of what would be copied without writing it",
+			},
+		},
+
+		Action: RunNodeCopyCommand,
+	}
+
+	return c
+}
+
+func RunNodeCopyCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 2 {
+		return fmt.Errorf("missing required positional arguments:\n\t<src>, the node to copy from\n\t<dst>, the node to copy to")
+	}
+
+	opts := &bao.CopyOptions{
+		KVPaths:          cCtx.StringSlice("kv"),
+		Policies:         cCtx.Bool("policies"),
+		Auth:             cCtx.Bool("auth"),
+		Mounts:           cCtx.Bool("mounts"),
+		UserpassPassword: cCtx.String("userpass-password"),
+	}
+
+	if len(opts.KVPaths) == 0 && !opts.Policies && !opts.Auth && !opts.Mounts {
+		return fmt.Errorf("nothing to copy; pass at least one of --kv, --policies, --auth, or --mounts")
+	}
+
+	src, err := bao.LoadNode(cCtx.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to load source node: %w", err)
+	}
+
+	dst, err := bao.LoadNode(cCtx.Args().Get(1))
+	if err != nil {
+		return fmt.Errorf("failed to load destination node: %w", err)
+	}
+
+	srcClient, err := src.GetClient()
+	if err != nil {
+		return fmt.Errorf("failed to get client for node %v: %w", src.Name, err)
+	}
+
+	dstClient, err := dst.GetClient()
+	if err != nil {
+		return fmt.Errorf("failed to get client for node %v: %w", dst.Name, err)
+	}
+
+	items, warnings, err := bao.PlanCopy(srcClient, dstClient, opts)
+	if err != nil {
+		return err
+	}
+
+	for index, warning := range warnings {
+		fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, warning)
+	}
+
+	for _, item := range items {
+		fmt.Printf("# ===== %v %v ===== #\n\n%v\n", item.Kind, item.Path, item.Diff())
+	}
+
+	if len(items) == 0 {
+		fmt.Printf("node %v already matches node %v\n", dst.Name, src.Name)
+		return nil
+	}
+
+	if cCtx.Bool("dry-run") {
+		return nil
+	}
+
+	for _, item := range items {
+		if err := item.Apply(dstClient); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("copied %d items from node %v to node %v\n", len(items), src.Name, dst.Name)
+	return nil
+}
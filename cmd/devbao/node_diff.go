@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeDiffCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "diff",
+		ArgsUsage: "<a> <b>",
+		Usage:     "compare mounts, auth methods, policies, audit devices, and KV listings of two running nodes",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "kv",
+				Usage: "KV path (mount or subtree) whose recursive key listing to compare; may be repeated",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "text",
+				Usage: "output format: `text` or `json`",
+			},
+		},
+
+		Action: RunNodeDiffCommand,
+	}
+
+	return c
+}
+
+func RunNodeDiffCommand(cCtx *cli.Context) error {
+	if cCtx.Args().Len() != 2 {
+		return fmt.Errorf("missing required positional arguments:\n\t<a>, the first node to compare\n\t<b>, the second node to compare")
+	}
+
+	format := cCtx.String("format")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown value for --format: valid values are `text` and `json`; got `%v`", format)
+	}
+
+	a, err := bao.LoadNode(cCtx.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	b, err := bao.LoadNode(cCtx.Args().Get(1))
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	differences, err := bao.DiffNodes(a, b, cCtx.StringSlice("kv"))
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		if differences == nil {
+			differences = []*bao.StateDifference{}
+		}
+
+		return json.NewEncoder(os.Stdout).Encode(differences)
+	}
+
+	for _, difference := range differences {
+		where := "changed"
+		switch difference.Change {
+		case bao.DiffOnlyA:
+			where = "only on " + a.Name
+		case bao.DiffOnlyB:
+			where = "only on " + b.Name
+		}
+
+		fmt.Printf("# ===== %v %v (%v) ===== #\n\n%v\n", difference.Kind, difference.Path, where, difference.Diff)
+	}
+
+	if len(differences) == 0 {
+		fmt.Printf("nodes %v and %v match\n", a.Name, b.Name)
+	} else {
+		fmt.Printf("nodes %v and %v differ in %d items\n", a.Name, b.Name, len(differences))
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func ExportFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "format",
+			Usage:    "deployment format to export: " + strings.Join(bao.ListExportFormats(), ", "),
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Value:   "",
+			Usage:   "directory to write exported files to; defaults to `<name>-<format>`",
+		},
+	}
+}
+
+func BuildNodeExportCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "export",
+		ArgsUsage: "<name>",
+		Usage:     "export the node as systemd, docker compose, or kubernetes definitions",
+
+		Action: RunNodeExportCommand,
+	}
+
+	c.Flags = append(c.Flags, ExportFlags()...)
+
+	return c
+}
+
+func PrintExportedFiles(written []string) {
+	for _, path := range written {
+		fmt.Printf(" - %v\n", path)
+	}
+}
+
+func RunNodeExportCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to export")
+	}
+
+	name := cCtx.Args().First()
+	format := cCtx.String("format")
+	output := cCtx.String("output")
+	if output == "" {
+		output = fmt.Sprintf("%v-%v", name, format)
+	}
+
+	node, err := bao.LoadNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	written, err := bao.ExportNode(node, format, output)
+	PrintExportedFiles(written)
+	if err != nil {
+		return fmt.Errorf("failed to export node: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeExportTerraformCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "export-terraform",
+		ArgsUsage: "<name>",
+		Usage:     "export the running node's mounts, auth methods, policies, and roles as Terraform configuration",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   "",
+				Usage:   "directory to write main.tf and variables.tf to; defaults to `<name>-terraform`",
+			},
+		},
+
+		Action: RunNodeExportTerraformCommand,
+	}
+
+	return c
+}
+
+func RunNodeExportTerraformCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to export")
+	}
+
+	name := cCtx.Args().First()
+	output := cCtx.String("output")
+	if output == "" {
+		output = fmt.Sprintf("%v-terraform", name)
+	}
+
+	node, err := bao.LoadNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	written, warnings, err := bao.ExportNodeTerraform(node, output)
+	for index, warning := range warnings {
+		fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, warning)
+	}
+
+	PrintExportedFiles(written)
+	if err != nil {
+		return fmt.Errorf("failed to export node: %w", err)
+	}
+
+	return nil
+}
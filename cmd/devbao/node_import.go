@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeImportCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "import",
+		ArgsUsage: "<name>",
+		Usage:     "register an existing server configuration as a managed node",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "config",
+				Usage:    "path to the existing server configuration (`config.hcl`)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "storage",
+				Usage: "existing storage directory to import; copied into the node unless --link is given",
+			},
+			&cli.BoolFlag{
+				Name:  "link",
+				Value: false,
+				Usage: "reference the storage directory in place rather than copying it",
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Value: "",
+				Usage: "type of node to run: `` for auto-detect preferring OpenBao, `bao` to run an OpenBao instance, or `vault` to run a HashiCorp Vault instance.",
+			},
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Value:   false,
+				Usage:   "overwrite an existing node, if present",
+			},
+		},
+
+		Action: RunNodeImportCommand,
+	}
+
+	return c
+}
+
+func RunNodeImportCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the name of the node to create")
+	}
+
+	name := cCtx.Args().First()
+	force := cCtx.Bool("force")
+	storage := cCtx.String("storage")
+	link := cCtx.Bool("link")
+
+	if link && storage == "" {
+		return fmt.Errorf("--link requires --storage, but was not provided")
+	}
+
+	if !force {
+		present, err := bao.NodeExists(name)
+		if err != nil {
+			return fmt.Errorf("error checking if node exists: %w", err)
+		}
+
+		if present {
+			return fmt.Errorf("refusing to overwrite existing node %v", name)
+		}
+	}
+
+	node, warnings, err := bao.ImportNode(name, cCtx.String("type"), cCtx.String("config"), storage, link)
+	for index, warning := range warnings {
+		fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, warning)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to import node: %w", err)
+	}
+
+	fmt.Printf("imported node %v; start it with `devbao node resume %v`\n", node.Name, node.Name)
+	return nil
+}
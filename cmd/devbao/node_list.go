@@ -66,7 +66,17 @@ func RunNodeListCommand(cCtx *cli.Context) error {
 			cluster = fmt.Sprintf(" [cluster: %v]", node.Cluster)
 		}
 
-		lines = append(lines, fmt.Sprintf(" - %v (%v)%v", name, state, cluster))
+		binary := ""
+		if node.Binary != "" {
+			binary = fmt.Sprintf(" [binary: %v]", node.Binary)
+		}
+
+		version := ""
+		if detected, err := node.BinaryVersion(); err == nil {
+			version = fmt.Sprintf(" [version: %v]", detected)
+		}
+
+		lines = append(lines, fmt.Sprintf(" - %v (%v)%v%v%v", name, state, cluster, binary, version))
 	}
 
 	fmt.Println(strings.Join(lines, "\n"))
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeMigrateStorageCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "migrate-storage",
+		ArgsUsage: "<name>",
+		Usage:     "move a node's data to another storage backend with `operator migrate`, then resume and unseal it",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "to",
+				Required: true,
+				Usage:    "storage backend to migrate to; choose between `raft` or `file`",
+			},
+		},
+
+		Action: RunNodeMigrateStorageCommand,
+	}
+
+	return c
+}
+
+func RunNodeMigrateStorageCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to migrate")
+	}
+
+	node, err := bao.LoadNode(cCtx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	to := cCtx.String("to")
+	fmt.Printf("migrating node %v from %v to %v storage...\n", node.Name, node.Config.StorageType, to)
+
+	if err := node.MigrateStorage(to); err != nil {
+		return err
+	}
+
+	fmt.Printf("node %v now uses %v storage\n", node.Name, to)
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+func BuildNodePackCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "pack",
+		ArgsUsage: "<name>",
+		Usage:     "bundle a node's metadata, configuration, and storage into a portable archive for sharing",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "path to write the bundle to; defaults to `<name>.tar.gz`",
+			},
+			&cli.StringFlag{
+				Name:  "secrets",
+				Value: bao.BundleSecretsInclude,
+				Usage: fmt.Sprintf("how to handle the node's token and unseal keys; one of %v", strings.Join(bao.ListBundleSecretsModes(), ", ")),
+			},
+			&cli.BoolFlag{
+				Name:  "exclude-tls",
+				Usage: "strip TLS material from listeners; the unpacked node serves plain HTTP",
+			},
+			&cli.BoolFlag{
+				Name:  "logs",
+				Usage: "include the node's server and audit logs",
+			},
+		},
+
+		Action: RunNodePackCommand,
+	}
+
+	c.Flags = append(c.Flags, PassphraseFlags()...)
+
+	return c
+}
+
+func PassphraseFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "passphrase-file",
+			Usage: "read the passphrase protecting encrypted secrets from this file rather than prompting for it",
+		},
+	}
+}
+
+// ReadPassphrase reads the bundle passphrase from --passphrase-file or,
+// failing that, prompts for it on the terminal; with confirm, it must be
+// entered twice.
+func ReadPassphrase(cCtx *cli.Context, confirm bool) ([]byte, error) {
+	if path := cCtx.String("passphrase-file"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+
+		passphrase := []byte(strings.TrimRight(string(data), "\r\n"))
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("passphrase file %v is empty", path)
+		}
+
+		return passphrase, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("no terminal to prompt for the passphrase on; use --passphrase-file")
+	}
+
+	fmt.Fprintf(os.Stderr, "passphrase: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	if confirm {
+		fmt.Fprintf(os.Stderr, "confirm passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+
+		if string(again) != string(passphrase) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+func RunNodePackCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to pack")
+	}
+
+	node, err := bao.LoadNode(cCtx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	opts := &bao.BundleOptions{
+		Secrets:    cCtx.String("secrets"),
+		ExcludeTLS: cCtx.Bool("exclude-tls"),
+		Logs:       cCtx.Bool("logs"),
+	}
+
+	if opts.Secrets == bao.BundleSecretsEncrypt {
+		opts.Passphrase, err = ReadPassphrase(cCtx, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	if node.Cluster != "" {
+		fmt.Fprintf(os.Stderr, "[warning] node %v is a member of cluster %v; the bundle holds this member alone and may lack quorum once unpacked\n", node.Name, node.Cluster)
+	}
+
+	output := cCtx.String("output")
+	if output == "" {
+		output = node.Name + ".tar.gz"
+	}
+
+	// Write to a temporary file first so that a failed pack never leaves a
+	// truncated bundle behind.
+	tmpPath := output + ".tmp"
+	bundleFile, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle (`%v`) for writing: %w", tmpPath, err)
+	}
+
+	defer os.Remove(tmpPath)
+	defer bundleFile.Close()
+
+	if err := node.Pack(bundleFile, opts); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, output); err != nil {
+		return fmt.Errorf("failed to move bundle into place: %w", err)
+	}
+
+	fmt.Printf("packed node %v into %v (secrets: %v)\n", node.Name, output, opts.Secrets)
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeRebuildCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "rebuild",
+		ArgsUsage: "<name>",
+		Usage:     "rebuild the node's pinned binary from its source checkout and restart the node on it",
+
+		Action: RunNodeRebuildCommand,
+	}
+
+	return c
+}
+
+func RunNodeRebuildCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to rebuild")
+	}
+
+	name := cCtx.Args().First()
+	node, err := bao.LoadNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	if node.Binary == "" {
+		return fmt.Errorf("node %v does not pin a binary; use `devbao node set-binary` with a binary from `devbao binary build`", name)
+	}
+
+	binary, err := bao.LoadBinary(node.Binary)
+	if err != nil {
+		return fmt.Errorf("node %v does not pin a registered binary: %w", name, err)
+	}
+
+	fmt.Printf("rebuilding %v from %v...\n", binary.Name, binary.Source)
+	binary, err = binary.Rebuild()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("built %v %v (commit %v)\n", binary.Product, binary.Version, binary.Commit)
+
+	if node.Exec != nil && node.Exec.ValidateRunning() == nil {
+		fmt.Printf("restarting node %v...\n", name)
+		if err := node.Restart(); err != nil {
+			return fmt.Errorf("failed to restart node: %w", err)
+		}
+	} else if _, err := EnsureRunning(node); err != nil {
+		return err
+	}
+
+	nodes, err := bao.ListNodes()
+	if err != nil {
+		return err
+	}
+
+	for _, other := range nodes {
+		if other == name {
+			continue
+		}
+
+		otherNode, err := bao.LoadNode(other)
+		if err == nil && otherNode.Binary == binary.Name {
+			fmt.Fprintf(os.Stderr, "[warning] node %v also pins %v; it runs the new build once restarted\n", other, binary.Name)
+		}
+	}
+
+	return nil
+}
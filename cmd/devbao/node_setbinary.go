@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeSetBinaryCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "set-binary",
+		Aliases:   []string{"s-b"},
+		ArgsUsage: "<name> [<binary>]",
+		Usage:     "pin the server binary of a node by registered name or path; omit it to unpin",
+
+		Action: RunNodeSetBinaryCommand,
+	}
+
+	return c
+}
+
+func RunNodeSetBinaryCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument:\n\t<name>, the node to pin\n\t<binary>, the registered name or path of the binary")
+	}
+
+	name := cCtx.Args().First()
+	node, err := bao.LoadNode(name)
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	binary := ""
+	if ref := cCtx.Args().Get(1); ref != "" {
+		binary, err = bao.PinBinaryRef(ref)
+		if err != nil {
+			return err
+		}
+	}
+
+	node.PinBinary(binary)
+	if err := node.SaveConfig(); err != nil {
+		return err
+	}
+
+	if node.Exec != nil && node.Exec.ValidateRunning() == nil {
+		fmt.Fprintf(os.Stderr, "[warning] node %v is running; stop and resume it to use the new binary\n", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openbao/devbao/pkg/bao"
+	"github.com/openbao/openbao/api/v2"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeSnapshotCommand() *cli.Command {
+	c := &cli.Command{
+		Name:    "snapshot",
+		Aliases: []string{"snap"},
+		Usage:   "commands for saving and restoring a node's raft snapshots",
+	}
+
+	c.Subcommands = append(c.Subcommands, BuildNodeSnapshotListCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeSnapshotRestoreCommand())
+	c.Subcommands = append(c.Subcommands, BuildNodeSnapshotSaveCommand())
+
+	return c
+}
+
+func SnapshotSaveFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "every",
+			Usage: "keep running in the foreground, taking a snapshot at this interval",
+		},
+		&cli.IntFlag{
+			Name:  "keep",
+			Usage: "after each snapshot, remove all but this many of the most recent snapshots; 0 keeps all",
+		},
+	}
+}
+
+func SnapshotRestoreFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "force",
+			Aliases: []string{"f"},
+			Usage:   "restore even if the snapshot was taken from a cluster with different keys (snapshot-force)",
+		},
+	}
+}
+
+// SaveSnapshots takes a snapshot through the client returned by source,
+// pruning the store afterwards. With --every, this repeats until
+// interrupted; failures are then reported as warnings rather than ending
+// the loop, as the cluster may briefly be without a leader.
+func SaveSnapshots(cCtx *cli.Context, store *bao.SnapshotStore, source func() (string, *api.Client, error)) error {
+	every := cCtx.Duration("every")
+	keep := cCtx.Int("keep")
+	if every < 0 || keep < 0 {
+		return fmt.Errorf("--every and --keep must not be negative")
+	}
+
+	save := func() error {
+		node, client, err := source()
+		if err != nil {
+			return err
+		}
+
+		snapshot, err := store.Save(node, client)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("saved snapshot %v from %v (index %v, version %v)\n", snapshot.Name, snapshot.Node, snapshot.Index, snapshot.Version)
+
+		removed, err := store.Prune(keep)
+		for _, old := range removed {
+			fmt.Printf("removed snapshot %v\n", old.Name)
+		}
+
+		return err
+	}
+
+	if every == 0 {
+		return save()
+	}
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		if err := save(); err != nil {
+			fmt.Fprintf(os.Stderr, "[warning] %v\n", err)
+		}
+
+		<-ticker.C
+	}
+}
+
+// RestoreSnapshot restores the named snapshot from the store, or the most
+// recent one if name is empty, through the given active node's client.
+func RestoreSnapshot(cCtx *cli.Context, store *bao.SnapshotStore, name string, client *api.Client) error {
+	if name == "" {
+		snapshots, err := store.List()
+		if err != nil {
+			return err
+		}
+
+		if len(snapshots) == 0 {
+			return fmt.Errorf("no snapshots to restore")
+		}
+
+		name = snapshots[len(snapshots)-1].Name
+	}
+
+	fmt.Printf("restoring snapshot %v...\n", name)
+
+	return store.Restore(name, client, cCtx.Bool("force"))
+}
+
+func PrintSnapshots(store *bao.SnapshotStore) error {
+	snapshots, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, snapshot := range snapshots {
+		version := snapshot.Version
+		if version == "" {
+			version = "unknown"
+		}
+
+		lines = append(lines, fmt.Sprintf(" - %v: index %v, term %v [node: %v] [version: %v] [size: %v]", snapshot.Name, snapshot.Index, snapshot.Term, snapshot.Node, version, snapshot.Size))
+	}
+
+	fmt.Println(strings.Join(lines, "\n"))
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeSnapshotListCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "list",
+		Aliases:   []string{"l"},
+		ArgsUsage: "<name>",
+		Usage:     "list the node's saved raft snapshots, oldest first",
+
+		Action: RunNodeSnapshotListCommand,
+	}
+
+	return c
+}
+
+func RunNodeSnapshotListCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to list snapshots of")
+	}
+
+	node, err := bao.LoadNode(cCtx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	return PrintSnapshots(node.Snapshots())
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeSnapshotRestoreCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "restore",
+		ArgsUsage: "<name> [snapshot]",
+		Usage:     "restore one of the node's raft snapshots, by default the most recent",
+		Flags:     SnapshotRestoreFlags(),
+
+		Action: RunNodeSnapshotRestoreCommand,
+	}
+
+	return c
+}
+
+func RunNodeSnapshotRestoreCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to restore")
+	}
+
+	node, err := bao.LoadNode(cCtx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	if node.Cluster != "" {
+		return fmt.Errorf("node %v is a member of cluster %v; use `devbao cluster snapshot restore` instead", node.Name, node.Cluster)
+	}
+
+	client, err := node.GetClient()
+	if err != nil {
+		return fmt.Errorf("failed to get client for node %v: %w", node.Name, err)
+	}
+
+	return RestoreSnapshot(cCtx, node.Snapshots(), cCtx.Args().Get(1), client)
+}
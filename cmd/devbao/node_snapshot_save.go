@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/openbao/devbao/pkg/bao"
+	"github.com/openbao/openbao/api/v2"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeSnapshotSaveCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "save",
+		ArgsUsage: "<name>",
+		Usage:     "save a raft snapshot of the node under its directory",
+		Flags:     SnapshotSaveFlags(),
+
+		Action: RunNodeSnapshotSaveCommand,
+	}
+
+	return c
+}
+
+func RunNodeSnapshotSaveCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <name>, the node to snapshot")
+	}
+
+	node, err := bao.LoadNode(cCtx.Args().First())
+	if err != nil {
+		return fmt.Errorf("failed to load node: %w", err)
+	}
+
+	return SaveSnapshots(cCtx, node.Snapshots(), func() (string, *api.Client, error) {
+		client, err := node.GetClient()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get client for node %v: %w", node.Name, err)
+		}
+
+		return node.Name, client, nil
+	})
+}
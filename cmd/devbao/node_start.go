@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 
@@ -35,6 +34,10 @@ func ServerFlags(name string) []cli.Flag {
 			Value:   false,
 			Usage:   "overwrite an existing node, if present",
 		},
+		&cli.StringFlag{
+			Name:  "binary",
+			Usage: "registered name or path of the server binary to run; otherwise, one is found on the PATH per --type",
+		},
 		&cli.StringSliceFlag{
 			Name:    "profiles",
 			Aliases: []string{"p"},
@@ -137,6 +140,10 @@ func RunNodeStartDevCommand(cCtx *cli.Context) error {
 		return fmt.Errorf("failed to build node: %w", err)
 	}
 
+	if err := pinNodeBinary(cCtx, node); err != nil {
+		return err
+	}
+
 	if err := node.Start(); err != nil {
 		return fmt.Errorf("failed to start node: %w", err)
 	}
@@ -150,22 +157,22 @@ func RunNodeStartDevCommand(cCtx *cli.Context) error {
 		return fmt.Errorf("failed to get client for node %v: %w", name, err)
 	}
 
-	for profileIndex, profile := range profiles {
-		warnings, err := bao.ProfileSetup(client, profile)
-		if len(warnings) != 0 || err != nil {
-			fmt.Fprintf(os.Stderr, "for profile [%d/%v]:\n", profileIndex, profile)
-		}
+	return ApplyProfiles(client, profiles)
+}
 
-		for index, warning := range warnings {
-			fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, warning)
-		}
+// pinNodeBinary pins the binary given by --binary, if any, to the node.
+func pinNodeBinary(cCtx *cli.Context, node *bao.Node) error {
+	if cCtx.String("binary") == "" {
+		return nil
+	}
 
-		if err != nil {
-			return err
-		}
+	binary, err := bao.PinBinaryRef(cCtx.String("binary"))
+	if err != nil {
+		return err
 	}
 
-	return nil
+	node.PinBinary(binary)
+	return node.SaveConfig()
 }
 
 func UnsealFlags() []cli.Flag {
@@ -208,6 +215,51 @@ func ProdServerFlags() []cli.Flag {
 	return ret
 }
 
+func ParseListener(listener string) (bao.Listener, error) {
+	if strings.HasPrefix(listener, "tcp:") {
+		return &bao.TCPListener{
+			Address: strings.TrimPrefix(listener, "tcp:"),
+		}, nil
+	} else if strings.HasPrefix(listener, "unix:") {
+		return &bao.UnixListener{
+			Path: strings.TrimPrefix(listener, "unix:"),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown type prefix: `%v`; supported values are `tcp:<bind address>` or `unix:<path>`", listener)
+}
+
+func ParseSeal(seal string) (bao.Seal, error) {
+	url, err := url.Parse(seal)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assume transit.
+
+	if url.User == nil || url.User.Username() == "" {
+		return nil, fmt.Errorf("malformed or missing user info: expected token in username for Transit: `%v`", url.User.String())
+	}
+
+	token := url.User.Username()
+	addr := fmt.Sprintf("%v://%v", url.Scheme, url.Host)
+
+	if !strings.Contains(url.Path, "/keys/") {
+		return nil, fmt.Errorf("malformed path: no `/keys/` segment: `%v`", url.Path)
+	}
+
+	parts := strings.Split(url.Path, "/keys/")
+	mount_path := strings.Join(parts[0:len(parts)-1], "/keys")
+	key_name := parts[len(parts)-1]
+
+	return &bao.TransitSeal{
+		Address:   addr,
+		Token:     token,
+		MountPath: mount_path,
+		KeyName:   key_name,
+	}, nil
+}
+
 func BuildNodeStartCommand() *cli.Command {
 	c := &cli.Command{
 		Name:    "start",
@@ -272,49 +324,22 @@ func RunNodeStartCommand(cCtx *cli.Context) error {
 
 	listeners := cCtx.StringSlice("listeners")
 	for index, listener := range listeners {
-		if strings.HasPrefix(listener, "tcp:") {
-			opts = append(opts, &bao.TCPListener{
-				Address: strings.TrimPrefix(listener, "tcp:"),
-			})
-		} else if strings.HasPrefix(listener, "unix:") {
-			opts = append(opts, &bao.UnixListener{
-				Path: strings.TrimPrefix(listener, "unix:"),
-			})
-		} else {
-			return fmt.Errorf("unknown type prefix for -listeners at index %d: `%v`; supported values are `tcp:<bind address>` or `unix:<path>`", index, listener)
+		parsed, err := ParseListener(listener)
+		if err != nil {
+			return fmt.Errorf("failed parsing -listeners at index %d: %w", index, err)
 		}
+
+		opts = append(opts, parsed)
 	}
 
 	seals := cCtx.StringSlice("seals")
 	for index, seal := range seals {
-		url, err := url.Parse(seal)
+		parsed, err := ParseSeal(seal)
 		if err != nil {
 			return fmt.Errorf("failed parsing seal's uri at index %d (`%v`): %w", index, seal, err)
 		}
 
-		// Assume transit.
-
-		if url.User == nil || url.User.Username() == "" {
-			return fmt.Errorf("malformed or missing user info: expected token in username for Transit: `%v`", url.User.String())
-		}
-
-		token := url.User.Username()
-		addr := fmt.Sprintf("%v://%v", url.Scheme, url.Host)
-
-		if !strings.Contains(url.Path, "/keys/") {
-			return fmt.Errorf("malformed path: no `/keys/` segment: `%v`", url.Path)
-		}
-
-		parts := strings.Split(url.Path, "/keys/")
-		mount_path := strings.Join(parts[0:len(parts)-1], "/keys")
-		key_name := parts[len(parts)-1]
-
-		opts = append(opts, &bao.TransitSeal{
-			Address:   addr,
-			Token:     token,
-			MountPath: mount_path,
-			KeyName:   key_name,
-		})
+		opts = append(opts, parsed)
 	}
 
 	if audit {
@@ -335,6 +360,10 @@ func RunNodeStartCommand(cCtx *cli.Context) error {
 		return fmt.Errorf("failed to build node: %w", err)
 	}
 
+	if err := pinNodeBinary(cCtx, node); err != nil {
+		return err
+	}
+
 	if err := node.Start(); err != nil {
 		return fmt.Errorf("failed to start node: %w", err)
 	}
@@ -364,19 +393,5 @@ func RunNodeStartCommand(cCtx *cli.Context) error {
 		return fmt.Errorf("failed to get client for node %v: %w", name, err)
 	}
 
-	for profileIndex, profile := range profiles {
-		warnings, err := bao.ProfileSetup(client, profile)
-		if len(warnings) != 0 || err != nil {
-			fmt.Fprintf(os.Stderr, "for profile [%d/%v]:\n", profileIndex, profile)
-		}
-
-		for index, warning := range warnings {
-			fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, warning)
-		}
-
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return ApplyProfiles(client, profiles)
 }
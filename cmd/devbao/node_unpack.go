@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BuildNodeUnpackCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "unpack",
+		ArgsUsage: "<bundle>",
+		Usage:     "create a node from a bundle made with `devbao node pack`",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "name",
+				Usage: "name for the new node; defaults to the packed node's name",
+			},
+			&cli.BoolFlag{
+				Name:  "resume",
+				Usage: "start (and unseal) the node once unpacked",
+			},
+		},
+
+		Action: RunNodeUnpackCommand,
+	}
+
+	c.Flags = append(c.Flags, PassphraseFlags()...)
+
+	return c
+}
+
+func RunNodeUnpackCommand(cCtx *cli.Context) error {
+	if !cCtx.Args().Present() {
+		return fmt.Errorf("missing required positional argument: <bundle>, the path to the bundle to unpack")
+	}
+
+	path := cCtx.Args().First()
+	bundleFile, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle (`%v`) for reading: %w", path, err)
+	}
+
+	defer bundleFile.Close()
+
+	node, warnings, err := bao.UnpackNode(bundleFile, cCtx.String("name"), func() ([]byte, error) {
+		return ReadPassphrase(cCtx, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	for index, warning := range warnings {
+		fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, warning)
+	}
+
+	fmt.Printf("unpacked node %v from %v\n", node.Name, path)
+
+	if !cCtx.Bool("resume") {
+		return nil
+	}
+
+	_, err = EnsureRunning(node)
+	return err
+}
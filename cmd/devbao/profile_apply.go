@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/openbao/devbao/pkg/bao"
+	"github.com/openbao/openbao/api/v2"
 
 	"github.com/urfave/cli/v2"
 )
@@ -46,3 +47,24 @@ func RunProfileApplyCommand(cCtx *cli.Context) error {
 	}
 	return err
 }
+
+// ApplyProfiles sets up each of the given profiles in order, reporting any
+// warnings along the way.
+func ApplyProfiles(client *api.Client, profiles []string) error {
+	for profileIndex, profile := range profiles {
+		warnings, err := bao.ProfileSetup(client, profile)
+		if len(warnings) != 0 || err != nil {
+			fmt.Fprintf(os.Stderr, "for profile [%d/%v]:\n", profileIndex, profile)
+		}
+
+		for index, warning := range warnings {
+			fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, warning)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/openbao/devbao/pkg/bao"
 
@@ -21,8 +22,13 @@ func BuildProfileListCommand() *cli.Command {
 }
 
 func RunProfileListCommand(cCtx *cli.Context) error {
-	for _, profile := range bao.ListProfiles() {
-		fmt.Printf(" - %v\n", profile)
+	profiles, warnings := bao.ListProfiles()
+	for index, warning := range warnings {
+		fmt.Fprintf(os.Stderr, " - [warning %d]: %v\n", index, warning)
+	}
+
+	for _, profile := range profiles {
+		fmt.Printf(" - %v\n", profile.Name)
 	}
 	return nil
 }
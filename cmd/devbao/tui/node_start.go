@@ -76,13 +76,17 @@ func NodeStartModel() tabModel {
 	model.ProdInitialize.Action = model.RefreshState
 	model.ProdUnseal.Action = model.RefreshState
 
-	for _, profiles := range bao.ListProfiles() {
-		desc := bao.ProfileDescription(profiles)
-		input := NewCheckbox(namespace, profiles, desc)
+	profiles, warnings := bao.ListProfiles()
+	for _, profile := range profiles {
+		input := NewCheckbox(namespace, profile.Name, profile.Description)
 		input.Action = model.RefreshState
 		model.Profiles = append(model.Profiles, input)
 	}
 
+	for index, warning := range warnings {
+		model.Message += fmt.Sprintf("\n - [warning %d]: %v\n", index, warning)
+	}
+
 	model.Create.Action = func() tea.Cmd { return model.DoCreate() }
 
 	model.Type.Focus()
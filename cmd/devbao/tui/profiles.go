@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -8,16 +10,18 @@ import (
 	"github.com/openbao/devbao/pkg/bao"
 )
 
-type profileItem string
+type profileItem struct{ profile *bao.Profile }
 
-func (i profileItem) Title() string       { return string(i) }
-func (i profileItem) Description() string { return bao.ProfileDescription(i.Title()) }
-func (i profileItem) FilterValue() string { return string(i) }
+func (i profileItem) Title() string       { return i.profile.Name }
+func (i profileItem) Description() string { return i.profile.Description }
+func (i profileItem) FilterValue() string { return i.profile.Name }
 
 type profiles struct {
 	items []list.Item
 	list  list.Model
 
+	Message string
+
 	Width  int
 	Height int
 }
@@ -51,8 +55,14 @@ func newProfilesListDelegate() list.DefaultDelegate {
 
 func ProfilesModel() tabModel {
 	var items []list.Item
-	for _, policy := range bao.ListProfiles() {
-		items = append(items, profileItem(policy))
+	available, warnings := bao.ListProfiles()
+	for _, profile := range available {
+		items = append(items, profileItem{profile})
+	}
+
+	var message string
+	for index, warning := range warnings {
+		message += fmt.Sprintf(" - [warning %d]: %v\n", index, warning)
 	}
 
 	pList := list.New(items, newProfilesListDelegate(), 20, 20)
@@ -61,8 +71,9 @@ func ProfilesModel() tabModel {
 	pList.DisableQuitKeybindings()
 
 	return &profiles{
-		items: items,
-		list:  pList,
+		items:   items,
+		list:    pList,
+		Message: message,
 	}
 }
 
@@ -112,5 +123,9 @@ func (m *profiles) UpdateTab(msg tea.Msg) (tabModel, tabModel, tea.Cmd) {
 }
 
 func (m *profiles) View() string {
+	if m.Message != "" {
+		return warningStyle.Width(m.Width).Render("Message:\n"+m.Message) + "\n" + m.list.View()
+	}
+
 	return m.list.View()
 }
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/openbao/devbao/pkg/bao"
+
+	"github.com/urfave/cli/v2"
+)
+
+func SpecFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "show what would be done without doing it",
+		},
+	}
+}
+
+func BuildUpCommand() *cli.Command {
+	c := &cli.Command{
+		Name:      "up",
+		ArgsUsage: "[spec]",
+		Usage:     "create or resume the nodes and clusters described by a spec file",
+		Description: "Converges this machine to the spec (default: devbao.hcl), in dependency order.\n" +
+			"Existing nodes and clusters are resumed and unsealed rather than recreated; those whose\n" +
+			"configuration differs from the spec are only recreated with --force.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "recreate nodes and clusters which differ from the spec, discarding their data",
+			},
+		},
+
+		Action: RunUpCommand,
+	}
+
+	c.Flags = append(c.Flags, SpecFlags()...)
+
+	return c
+}
+
+func LoadSpecArg(cCtx *cli.Context) (*bao.Spec, error) {
+	if cCtx.Args().Len() > 1 {
+		return nil, fmt.Errorf("unexpected positional arguments; expected at most one spec file: %v", cCtx.Args().Slice())
+	}
+
+	path := cCtx.Args().First()
+	if path == "" {
+		path = "devbao.hcl"
+		if _, err := os.Stat(path); err != nil {
+			path = "devbao.json"
+		}
+	}
+
+	return bao.LoadSpec(path)
+}
+
+func RunUpCommand(cCtx *cli.Context) error {
+	spec, err := LoadSpecArg(cCtx)
+	if err != nil {
+		return err
+	}
+
+	order, err := spec.Order()
+	if err != nil {
+		return err
+	}
+
+	force := cCtx.Bool("force")
+	dryRun := cCtx.Bool("dry-run")
+
+	for _, resource := range order {
+		switch tRes := resource.(type) {
+		case *bao.SpecNode:
+			err = UpNode(tRes, force, dryRun)
+		case *bao.SpecCluster:
+			err = UpCluster(tRes, force, dryRun)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to bring up %v: %w", resource.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// specTransitSeal builds a seal using the auto-unseal key of the transit
+// profile on the named node.
+func specTransitSeal(name string) (bao.Seal, error) {
+	node, err := bao.LoadNode(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transit seal node %v: %w", name, err)
+	}
+
+	// Profiles are applied again whenever a dev mode node is resumed, which
+	// creates a new auto-unseal key that cannot decrypt the old seal.
+	if node.Config.Dev != nil {
+		return nil, fmt.Errorf("transit seal node %v runs in dev mode; its auto-unseal key does not survive restarts", name)
+	}
+
+	addr, _, err := node.GetConnectAddr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &bao.TransitSeal{
+		Address:   addr,
+		Token:     node.Token,
+		MountPath: "transit",
+		KeyName:   "auto-unseal",
+	}, nil
+}
+
+func specSeals(uris []string, transit string, dryRun bool) ([]bao.Seal, error) {
+	var seals []bao.Seal
+	for index, seal := range uris {
+		parsed, err := ParseSeal(seal)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing seal's uri at index %d (`%v`): %w", index, seal, err)
+		}
+
+		seals = append(seals, parsed)
+	}
+
+	if transit != "" {
+		seal, err := specTransitSeal(transit)
+		if err != nil {
+			if dryRun {
+				// The transit node may only be created by this run.
+				return seals, nil
+			}
+
+			return nil, err
+		}
+
+		seals = append(seals, seal)
+	}
+
+	return seals, nil
+}
+
+func specNodeOpts(spec *bao.SpecNode, dryRun bool) ([]bao.NodeConfigOpt, error) {
+	var opts []bao.NodeConfigOpt
+	if spec.Dev {
+		opts = append(opts, &bao.DevConfig{
+			Token:   spec.Token,
+			Address: spec.Address,
+		})
+	} else {
+		switch spec.Storage {
+		case "raft":
+			opts = append(opts, &bao.RaftStorage{})
+		case "file":
+			opts = append(opts, &bao.FileStorage{})
+		case "inmem":
+			opts = append(opts, &bao.InmemStorage{})
+		}
+
+		for index, listener := range spec.Listeners {
+			parsed, err := ParseListener(listener)
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing listeners at index %d: %w", index, err)
+			}
+
+			opts = append(opts, parsed)
+		}
+
+		seals, err := specSeals(spec.Seals, spec.TransitSeal, dryRun)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, seal := range seals {
+			opts = append(opts, seal)
+		}
+	}
+
+	if spec.Audit {
+		opts = append(opts, &bao.FileAudit{})
+		opts = append(opts, &bao.FileAudit{
+			CommonAudit: bao.CommonAudit{
+				LogRaw: true,
+			},
+		})
+	}
+
+	if spec.UI {
+		opts = append(opts, &bao.UI{Enabled: true})
+	}
+
+	return opts, nil
+}
+
+func configsEqual(a *bao.NodeConfig, b *bao.NodeConfig) (bool, error) {
+	if err := a.Validate(); err != nil {
+		return false, err
+	}
+
+	if err := b.Validate(); err != nil {
+		return false, err
+	}
+
+	aJson, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+
+	bJson, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+
+	return string(aJson) == string(bJson), nil
+}
+
+// EnsureRunning resumes the node if it is stopped, unsealing it with its
+// stored keys unless it is auto-unsealed. It reports whether the node had
+// to be resumed.
+func EnsureRunning(node *bao.Node) (bool, error) {
+	if node.Exec != nil {
+		if err := node.Exec.ValidateRunning(); err == nil {
+			return false, nil
+		}
+	}
+
+	fmt.Printf("resuming node %v...\n", node.Name)
+	if err := node.Resume(); err != nil {
+		return false, err
+	}
+
+	if node.Config.Dev != nil || len(node.UnsealKeys) == 0 || len(node.Config.Seals) > 0 {
+		return true, nil
+	}
+
+	if _, err := node.Unseal(); err != nil {
+		return true, fmt.Errorf("failed to unseal node: %w", err)
+	}
+
+	// TODO: use a client request with proper back-off to determine
+	// when the node is responding.
+	time.Sleep(500 * time.Millisecond)
+
+	return true, nil
+}
+
+func UpNode(spec *bao.SpecNode, force bool, dryRun bool) error {
+	opts, err := specNodeOpts(spec, dryRun)
+	if err != nil {
+		return err
+	}
+
+	present, err := bao.NodeExists(spec.Name)
+	if err != nil {
+		return fmt.Errorf("error checking if node exists: %w", err)
+	}
+
+	if present {
+		node, err := bao.LoadNode(spec.Name)
+		if err != nil {
+			return err
+		}
+
+		var desired bao.NodeConfig
+		if err := desired.ApplyOpts(opts...); err != nil {
+			return err
+		}
+
+		equal, err := configsEqual(&node.Config, &desired)
+		if err != nil {
+			return fmt.Errorf("failed to compare node %v against spec: %w", spec.Name, err)
+		}
+
+		if equal || !force {
+			if !equal {
+				fmt.Fprintf(os.Stderr, "[warning] node %v differs from the spec; use --force to recreate it\n", spec.Name)
+			}
+
+			if dryRun {
+				fmt.Printf("would ensure node %v is running\n", spec.Name)
+				return nil
+			}
+
+			resumed, err := EnsureRunning(node)
+			if err != nil || !resumed || node.Config.Dev == nil {
+				return err
+			}
+
+			// Dev mode storage does not persist, so profiles must be
+			// applied again.
+			client, err := node.GetClient()
+			if err != nil {
+				return fmt.Errorf("failed to get client for node %v: %w", spec.Name, err)
+			}
+
+			return ApplyProfiles(client, spec.Profiles)
+		}
+
+		if dryRun {
+			fmt.Printf("would recreate node %v\n", spec.Name)
+			return nil
+		}
+
+		fmt.Printf("recreating node %v...\n", spec.Name)
+		if err := node.Kill(); err == nil && node.Exec != nil {
+			_ = node.Exec.WaitStopped()
+		}
+
+		if err := node.Clean(false); err != nil {
+			return fmt.Errorf("failed to remove node %v: %w", spec.Name, err)
+		}
+	} else if dryRun {
+		fmt.Printf("would create node %v\n", spec.Name)
+		return nil
+	}
+
+	fmt.Printf("starting node %v...\n", spec.Name)
+	node, err := bao.BuildNode(spec.Name, spec.Type, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to build node: %w", err)
+	}
+
+	if err := node.Start(); err != nil {
+		return fmt.Errorf("failed to start node: %w", err)
+	}
+
+	if !spec.Dev {
+		if err := node.Initialize(); err != nil {
+			return fmt.Errorf("failed to initialize node: %w", err)
+		}
+
+		if _, err := node.Unseal(); err != nil {
+			return fmt.Errorf("failed to unseal node: %w", err)
+		}
+
+		// TODO: use a client request with proper back-off to determine
+		// when the node is responding.
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	if err := node.PostInitializeUnseal(); err != nil {
+		return fmt.Errorf("failed to apply post-unseal initialization; %w", err)
+	}
+
+	client, err := node.GetClient()
+	if err != nil {
+		return fmt.Errorf("failed to get client for node %v: %w", spec.Name, err)
+	}
+
+	return ApplyProfiles(client, spec.Profiles)
+}
+
+// clusterDifference describes the first way in which an existing cluster
+// differs from its spec, or returns an empty string when it matches.
+func clusterDifference(cluster *bao.Cluster, spec *bao.SpecCluster, seals []bao.Seal) (string, error) {
+	if len(cluster.Nodes) != spec.Count {
+		return fmt.Sprintf("it has %v nodes but the spec has %v", len(cluster.Nodes), spec.Count), nil
+	}
+
+	if cluster.RetryJoin != spec.RetryJoin {
+		return fmt.Sprintf("retry_join is %v but the spec has %v", cluster.RetryJoin, spec.RetryJoin), nil
+	}
+
+	if !slices.Equal(cluster.Profiles, spec.Profiles) {
+		return fmt.Sprintf("it has profiles %v but the spec has %v", cluster.Profiles, spec.Profiles), nil
+	}
+
+	members, err := haClusterMembers(spec.Name, spec.Listen, spec.Port, spec.Count, spec.NonVoters, spec.RetryJoin, seals)
+	if err != nil {
+		return "", err
+	}
+
+	for index, member := range members {
+		if cluster.Nodes[index] != member.Name {
+			return fmt.Sprintf("it has node %v where the spec has %v", cluster.Nodes[index], member.Name), nil
+		}
+
+		node, err := bao.LoadNode(member.Name)
+		if err != nil {
+			return "", err
+		}
+
+		if node.Type != spec.NodeType {
+			return fmt.Sprintf("node %v has type `%v` but the spec has `%v`", node.Name, node.Type, spec.NodeType), nil
+		}
+
+		var desired bao.NodeConfig
+		if err := desired.ApplyOpts(member.Opts...); err != nil {
+			return "", err
+		}
+
+		desired.ClusterProxy = spec.FaultProxy
+
+		equal, err := configsEqual(&node.Config, &desired)
+		if err != nil {
+			return "", err
+		}
+
+		if !equal {
+			return fmt.Sprintf("the configuration of node %v differs", node.Name), nil
+		}
+	}
+
+	return "", nil
+}
+
+func UpCluster(spec *bao.SpecCluster, force bool, dryRun bool) error {
+	seals, err := specSeals(spec.Seals, spec.TransitSeal, dryRun)
+	if err != nil {
+		return err
+	}
+
+	present, err := bao.ClusterExists(spec.Name)
+	if err != nil {
+		return fmt.Errorf("error checking if cluster exists: %w", err)
+	}
+
+	if present {
+		cluster, err := bao.LoadCluster(spec.Name)
+		if err != nil {
+			return err
+		}
+
+		difference, err := clusterDifference(cluster, spec, seals)
+		if err != nil {
+			return fmt.Errorf("failed to compare cluster %v against spec: %w", spec.Name, err)
+		}
+
+		differs := difference != ""
+		if !differs || !force {
+			if differs {
+				fmt.Fprintf(os.Stderr, "[warning] cluster %v differs from the spec: %v; use --force to recreate it\n", spec.Name, difference)
+			}
+
+			if dryRun {
+				fmt.Printf("would ensure cluster %v is running\n", spec.Name)
+				return nil
+			}
+
+			for _, name := range cluster.Nodes {
+				node, err := bao.LoadNode(name)
+				if err != nil {
+					return err
+				}
+
+				if _, err := EnsureRunning(node); err != nil {
+					return fmt.Errorf("failed to resume node %v: %w", name, err)
+				}
+			}
+
+			return nil
+		}
+
+		if dryRun {
+			fmt.Printf("would recreate cluster %v\n", spec.Name)
+			return nil
+		}
+
+		fmt.Printf("recreating cluster %v...\n", spec.Name)
+		if err := cluster.Clean(false); err != nil {
+			return fmt.Errorf("failed to remove cluster %v: %w", spec.Name, err)
+		}
+	} else if dryRun {
+		fmt.Printf("would create cluster %v with %v nodes\n", spec.Name, spec.Count)
+		return nil
+	}
+
+	_, err = StartHACluster(spec.Name, spec.NodeType, spec.Listen, spec.Port, spec.Count, spec.NonVoters, spec.RetryJoin, spec.FaultProxy, seals, nil, spec.Profiles)
+	return err
+}
@@ -0,0 +1,276 @@
+package bao
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// AdoptCandidate describes a running server process which was not launched
+// by devbao.
+type AdoptCandidate struct {
+	Pid     int      `json:"pid"`
+	Binary  string   `json:"binary"`
+	Args    []string `json:"args"`
+	Cwd     string   `json:"cwd"`
+	Configs []string `json:"configs"`
+	Dev     bool     `json:"dev"`
+}
+
+func (a *AdoptCandidate) Product() string {
+	if filepath.Base(a.Binary) == "vault" {
+		return "vault"
+	}
+
+	return "bao"
+}
+
+func isServerBinary(path string) bool {
+	switch filepath.Base(path) {
+	case "bao", "openbao", "vault":
+		return true
+	}
+
+	return false
+}
+
+// flagValue returns the value of a single-dash or double-dash flag at the
+// given index of args, handling both `-flag=value` and `-flag value` forms,
+// along with the number of arguments consumed.
+func flagValue(args []string, index int, name string) (string, int, bool) {
+	arg := strings.TrimPrefix(strings.TrimPrefix(args[index], "-"), "-")
+	if arg == name {
+		if index+1 < len(args) {
+			return args[index+1], 2, true
+		}
+
+		return "", 1, true
+	}
+
+	if strings.HasPrefix(arg, name+"=") {
+		return strings.TrimPrefix(arg, name+"="), 1, true
+	}
+
+	return "", 1, false
+}
+
+// FindUntrackedServers scans running processes for OpenBao or Vault servers
+// which are not tracked by any existing node.
+func FindUntrackedServers() ([]*AdoptCandidate, error) {
+	tracked := map[int]bool{}
+
+	nodes, err := ListNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range nodes {
+		node, err := LoadNodeUnvalidated(name)
+		if err != nil {
+			continue
+		}
+
+		if node.Exec != nil && node.Exec.Pid != 0 {
+			tracked[node.Exec.Pid] = true
+		}
+	}
+
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	var results []*AdoptCandidate
+	for _, proc := range procs {
+		pid := int(proc.Pid)
+		if tracked[pid] {
+			continue
+		}
+
+		exe, err := proc.Exe()
+		if err != nil || !isServerBinary(exe) {
+			continue
+		}
+
+		cmdline, err := proc.CmdlineSlice()
+		if err != nil || len(cmdline) < 2 || cmdline[1] != "server" {
+			continue
+		}
+
+		// The process may have exited since it was listed, or belong to
+		// another user.
+		cwd, err := proc.Cwd()
+		if err != nil {
+			continue
+		}
+
+		candidate := &AdoptCandidate{
+			Pid:    pid,
+			Binary: exe,
+			Cwd:    cwd,
+		}
+
+		// Rewrite relative configuration paths so that the command line
+		// remains valid when run from the node's directory.
+		args := cmdline[1:]
+		for index := 0; index < len(args); {
+			if args[index] == "-dev" || args[index] == "--dev" {
+				candidate.Dev = true
+			}
+
+			value, consumed, ok := flagValue(args, index, "config")
+			if ok && value != "" {
+				if !filepath.IsAbs(value) {
+					value = filepath.Join(cwd, value)
+				}
+
+				candidate.Configs = append(candidate.Configs, value)
+				candidate.Args = append(candidate.Args, "-config="+value)
+			} else {
+				candidate.Args = append(candidate.Args, args[index:index+consumed]...)
+			}
+
+			index += consumed
+		}
+
+		results = append(results, candidate)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Pid < results[j].Pid })
+
+	return results, nil
+}
+
+func readAdoptedConfigs(paths []string) (string, string, error) {
+	var contents []string
+	configDir := ""
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to stat configuration (%v): %w", path, err)
+		}
+
+		files := []string{path}
+		if info.IsDir() {
+			files, err = filepath.Glob(filepath.Join(path, "*.hcl"))
+			if err != nil {
+				return "", "", fmt.Errorf("failed to list configuration directory (%v): %w", path, err)
+			}
+		}
+
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to read configuration (%v): %w", file, err)
+			}
+
+			if configDir == "" {
+				configDir = filepath.Dir(file)
+			}
+
+			contents = append(contents, string(data))
+		}
+	}
+
+	return strings.Join(contents, "\n"), configDir, nil
+}
+
+// AdoptNode registers the running process described by the candidate as a
+// new node. Its storage is referenced in place and its original command line
+// is reused on resume.
+func AdoptNode(name string, candidate *AdoptCandidate) (*Node, []string, error) {
+	n := &Node{
+		Name:    name,
+		Type:    candidate.Product(),
+		Adopted: true,
+	}
+
+	var warnings []string
+	if candidate.Dev {
+		n.Config.Dev = &DevConfig{
+			Address: "127.0.0.1:8200",
+		}
+
+		for index := range candidate.Args {
+			if value, _, ok := flagValue(candidate.Args, index, "dev-listen-address"); ok {
+				n.Config.Dev.Address = value
+			}
+
+			if value, _, ok := flagValue(candidate.Args, index, "dev-root-token-id"); ok {
+				n.Config.Dev.Token = value
+			}
+		}
+
+		if n.Config.Dev.Token == "" {
+			warnings = append(warnings, "dev mode server has a generated root token; set it with `devbao node set-token`")
+		}
+	} else {
+		if len(candidate.Configs) == 0 {
+			return nil, nil, fmt.Errorf("process %d has neither -dev nor -config arguments", candidate.Pid)
+		}
+
+		contents, configDir, err := readAdoptedConfigs(candidate.Configs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cfg, cWarnings, err := ParseServerConfig(contents, configDir)
+		warnings = append(warnings, cWarnings...)
+		if err != nil {
+			return nil, warnings, err
+		}
+
+		n.Config = *cfg
+		warnings = append(warnings, "set the root token and unseal keys with `devbao node set-token` and `devbao node set-unseal`")
+	}
+
+	if err := n.Validate(); err != nil {
+		return nil, warnings, fmt.Errorf("failed to validate adopted configuration: %w", err)
+	}
+
+	directory := n.GetDirectory()
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, warnings, fmt.Errorf("failed to create node directory (%v): %w", directory, err)
+	}
+
+	// Materialize any TLS material so that clients can connect; the
+	// rendered configuration itself is only informational.
+	if n.Config.Dev == nil {
+		config, err := n.RenderConfig()
+		if err != nil {
+			return nil, warnings, fmt.Errorf("failed to build node's configuration (%s): %w", n.Name, err)
+		}
+
+		if _, err := n.SaveInstanceConfig(config); err != nil {
+			return nil, warnings, fmt.Errorf("error persisting node configuration: %w", err)
+		}
+	}
+
+	addr, _, _, err := n.Config.GetConnectAddr(directory)
+	if err != nil {
+		return nil, warnings, fmt.Errorf("failed to infer address of process %d: %w", candidate.Pid, err)
+	}
+
+	n.Exec = &ExecEnvironment{
+		Binary:    candidate.Binary,
+		Args:      candidate.Args,
+		Directory: directory,
+
+		ConnectAddress: addr,
+	}
+
+	if err := n.Exec.SaveConfig(candidate.Pid); err != nil {
+		return nil, warnings, fmt.Errorf("failed to save exec config: %w", err)
+	}
+
+	if err := n.SaveConfig(); err != nil {
+		return nil, warnings, fmt.Errorf("failed saving adopted node: %w", err)
+	}
+
+	return n, warnings, nil
+}
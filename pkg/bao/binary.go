@@ -0,0 +1,394 @@
+package bao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openbao/devbao/pkg/utils"
+)
+
+const (
+	BinaryJsonName = "binary.json"
+)
+
+// Binary is a server binary registered under a name, so that nodes can pin
+// it rather than resolving whichever binary is on the PATH.
+type Binary struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Product string `json:"product"`
+	Version string `json:"version"`
+
+	// Binaries built by devbao record the checkout and commit they were
+	// built from, so that they can be rebuilt.
+	Source string `json:"source,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	Make   bool   `json:"make,omitempty"`
+}
+
+func BinaryBaseDirectory() string {
+	usr, _ := user.Current()
+	dir := usr.HomeDir
+
+	return filepath.Join(dir, ".local/share/devbao/binaries")
+}
+
+func (b *Binary) GetDirectory() string {
+	return filepath.Join(BinaryBaseDirectory(), b.Name)
+}
+
+func ListBinaries() ([]string, error) {
+	dir := BinaryBaseDirectory()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create binary directory (%v): %w", dir, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing binary directory (`%v`): %w", dir, err)
+	}
+
+	var results []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			results = append(results, entry.Name())
+		}
+	}
+
+	return results, nil
+}
+
+func BinaryExists(name string) (bool, error) {
+	binaries, err := ListBinaries()
+	if err != nil {
+		return false, err
+	}
+
+	for _, binary := range binaries {
+		if binary == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func LoadBinary(name string) (*Binary, error) {
+	path := filepath.Join(BinaryBaseDirectory(), name, BinaryJsonName)
+	binaryFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open binary file (`%v`) for reading: %w", path, err)
+	}
+
+	defer binaryFile.Close()
+
+	var binary Binary
+	if err := json.NewDecoder(binaryFile).Decode(&binary); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal binary: %w", err)
+	}
+
+	return &binary, nil
+}
+
+func (b *Binary) SaveConfig() error {
+	if b.Name == "" || strings.ContainsRune(b.Name, os.PathSeparator) {
+		return fmt.Errorf("invalid binary name: `%v`", b.Name)
+	}
+
+	directory := b.GetDirectory()
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return fmt.Errorf("failed to create binary directory (%v): %w", directory, err)
+	}
+
+	path := filepath.Join(directory, BinaryJsonName)
+	binaryFile, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open binary file (`%v`) for writing: %w", path, err)
+	}
+
+	defer binaryFile.Close()
+
+	if err := json.NewEncoder(binaryFile).Encode(b); err != nil {
+		return fmt.Errorf("failed to marshal binary: %w", err)
+	}
+
+	return nil
+}
+
+// AddBinary registers the binary at path under name, detecting its product
+// and version.
+func AddBinary(name string, path string) (*Binary, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve binary path: %w", err)
+	}
+
+	product, version, err := DetectVersion(path)
+	if err != nil {
+		return nil, err
+	}
+
+	binary := &Binary{
+		Name:    name,
+		Path:    path,
+		Product: product,
+		Version: version,
+	}
+
+	return binary, binary.SaveConfig()
+}
+
+// Remove unregisters the binary. Binaries built by devbao live in the
+// registry and are removed along with it; binaries added from elsewhere are
+// left in place.
+func (b *Binary) Remove() error {
+	return os.RemoveAll(b.GetDirectory())
+}
+
+// DetectVersion runs `<binary> version`, returning the product and version
+// it reports, e.g., `OpenBao` and `v2.0.1`.
+func DetectVersion(path string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "version").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to run `%v version`: %w", path, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) < 2 {
+		return "", "", fmt.Errorf("unexpected output from `%v version`: %q", path, string(output))
+	}
+
+	return fields[0], fields[1], nil
+}
+
+// ResolveBinary translates a binary reference, either the name of a
+// registered binary or a path, into the path of an executable.
+func ResolveBinary(ref string) (string, error) {
+	present, err := BinaryExists(ref)
+	if err != nil {
+		return "", fmt.Errorf("error checking if binary exists: %w", err)
+	}
+
+	path := ref
+	if present {
+		binary, err := LoadBinary(ref)
+		if err != nil {
+			return "", err
+		}
+
+		path = binary.Path
+	} else if !strings.ContainsRune(ref, os.PathSeparator) {
+		return "", fmt.Errorf("unknown binary `%v`: not registered with `devbao binary add` and not a path", ref)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to find binary `%v`: %w", ref, err)
+	}
+
+	if info.IsDir() || info.Mode()&0o111 == 0 {
+		return "", fmt.Errorf("binary %v is not an executable file", path)
+	}
+
+	return path, nil
+}
+
+// ServerBinary resolves the path of the server binary the node runs: its
+// pinned binary, if any; the original binary of adopted nodes; or else the
+// first binary on the PATH matching the node's type.
+func (n *Node) ServerBinary() (string, error) {
+	switch {
+	case n.Binary != "":
+		return ResolveBinary(n.Binary)
+	case n.Adopted:
+		if n.Exec == nil || n.Exec.Binary == "" {
+			return "", fmt.Errorf("adopted node %v has no recorded binary", n.Name)
+		}
+
+		return n.Exec.Binary, nil
+	case n.Type == "":
+		return findBestBinary()
+	case n.Type == "bao":
+		binary, err := expandBinary("openbao")
+		if err != nil {
+			binary, err = expandBinary("bao")
+		}
+
+		return binary, err
+	case n.Type == "vault":
+		return expandBinary("vault")
+	default:
+		return "", fmt.Errorf("unknown execution type: `%s`", n.Type)
+	}
+}
+
+// PinBinaryRef validates a binary reference for pinning to a node:
+// registered names are kept, while paths are made absolute.
+func PinBinaryRef(ref string) (string, error) {
+	path, err := ResolveBinary(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if present, _ := BinaryExists(ref); present {
+		return ref, nil
+	}
+
+	return filepath.Abs(path)
+}
+
+// PinBinary pins the node to a binary reference from PinBinaryRef, or
+// unpins it when empty, recording the binary's version. The node is not
+// saved.
+func (n *Node) PinBinary(binary string) {
+	n.Binary = binary
+	n.Version, _ = n.detectBinaryVersion()
+}
+
+// BinaryVersion reports the version of the binary the node runs, as
+// recorded when it was last started or pinned; nodes without a recorded
+// version have it detected instead.
+func (n *Node) BinaryVersion() (string, error) {
+	if n.Version != "" {
+		return n.Version, nil
+	}
+
+	return n.detectBinaryVersion()
+}
+
+// detectBinaryVersion finds the version of the node's pinned registry
+// entry, if any, or else runs the binary last used to run it.
+func (n *Node) detectBinaryVersion() (string, error) {
+	if n.Binary != "" {
+		present, err := BinaryExists(n.Binary)
+		if err != nil {
+			return "", err
+		}
+
+		if present {
+			binary, err := LoadBinary(n.Binary)
+			if err != nil {
+				return "", err
+			}
+
+			return binary.Version, nil
+		}
+	}
+
+	path := n.Binary
+	if path == "" && n.Exec != nil {
+		path = n.Exec.Binary
+	}
+
+	if path == "" {
+		return "", fmt.Errorf("node %v has not been started", n.Name)
+	}
+
+	_, version, err := DetectVersion(path)
+	return version, err
+}
+
+// BuildBinary builds the server from a local source checkout, either with
+// `go build` or with `make bin`, and registers the result under name. The
+// built binary is copied into the registry so that later changes to the
+// checkout do not affect nodes pinned to it.
+func BuildBinary(name string, source string, useMake bool) (*Binary, error) {
+	source, err := filepath.Abs(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source path: %w", err)
+	}
+
+	commit, err := gitCommit(source)
+	if err != nil {
+		return nil, err
+	}
+
+	binary := &Binary{
+		Name:   name,
+		Source: source,
+		Commit: commit,
+		Make:   useMake,
+	}
+
+	directory := binary.GetDirectory()
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create binary directory (%v): %w", directory, err)
+	}
+
+	binary.Path = filepath.Join(directory, "bao")
+
+	// Build to a temporary path and rename it into place: nodes running the
+	// previous build keep their (now unlinked) executable.
+	tmpPath := binary.Path + ".tmp"
+	var cmd *exec.Cmd
+	if useMake {
+		cmd = exec.Command("make", "bin")
+	} else {
+		cmd = exec.Command("go", "build", "-o", tmpPath, ".")
+	}
+
+	cmd.Dir = source
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to build %v (cli: %v): %w", source, cmd.Args, err)
+	}
+
+	if useMake {
+		if err := utils.CopyFile(filepath.Join(source, "bin", "bao"), tmpPath); err != nil {
+			return nil, fmt.Errorf("failed to copy built binary: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, binary.Path); err != nil {
+		return nil, fmt.Errorf("failed to move built binary into place: %w", err)
+	}
+
+	binary.Product, binary.Version, err = DetectVersion(binary.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return binary, binary.SaveConfig()
+}
+
+// Rebuild builds the binary again from its recorded source checkout.
+func (b *Binary) Rebuild() (*Binary, error) {
+	if b.Source == "" {
+		return nil, fmt.Errorf("binary %v was not built from source by devbao; register a new build with `devbao binary build`", b.Name)
+	}
+
+	return BuildBinary(b.Name, b.Source, b.Make)
+}
+
+// gitCommit returns the checkout's HEAD commit, suffixed with `-dirty` when
+// it has uncommitted changes.
+func gitCommit(source string) (string, error) {
+	output, err := exec.Command("git", "-C", source, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git commit of %v (is it a git checkout?): %w", source, err)
+	}
+
+	commit := strings.TrimSpace(string(output))
+
+	status, err := exec.Command("git", "-C", source, "status", "--porcelain").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git status of %v: %w", source, err)
+	}
+
+	if len(strings.TrimSpace(string(status))) > 0 {
+		commit += "-dirty"
+	}
+
+	return commit, nil
+}
@@ -0,0 +1,450 @@
+package bao
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/utils"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	BundleMetaName = "bundle.json"
+
+	BundleSecretsInclude = "include"
+	BundleSecretsExclude = "exclude"
+	BundleSecretsEncrypt = "encrypt"
+)
+
+func ListBundleSecretsModes() []string {
+	return []string{
+		BundleSecretsInclude,
+		BundleSecretsExclude,
+		BundleSecretsEncrypt,
+	}
+}
+
+// BundleOptions controls what a node bundle carries: whether the node's
+// token and unseal keys are included, left out, or encrypted with a
+// passphrase; whether listener TLS material is kept; and whether logs are
+// added.
+type BundleOptions struct {
+	Secrets    string
+	Passphrase []byte
+	ExcludeTLS bool
+	Logs       bool
+}
+
+// bundleMeta is stored as bundle.json at the root of a node bundle.
+type bundleMeta struct {
+	Node      string `json:"node"`
+	Directory string `json:"directory"`
+	Binary    string `json:"binary,omitempty"`
+	Version   string `json:"version,omitempty"`
+
+	Secrets          string            `json:"secrets"`
+	EncryptedSecrets *encryptedSecrets `json:"encrypted_secrets,omitempty"`
+}
+
+type bundleSecrets struct {
+	Token      string   `json:"token"`
+	UnsealKeys []string `json:"unseal_keys,omitempty"`
+}
+
+type encryptedSecrets struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func bundleCipher(passphrase []byte, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey(passphrase, salt, 1, 64*1024, 4, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func encryptSecrets(secrets *bundleSecrets, passphrase []byte) (*encryptedSecrets, error) {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	result := &encryptedSecrets{Salt: make([]byte, 16)}
+	if _, err := rand.Read(result.Salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aead, err := bundleCipher(passphrase, result.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(result.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	result.Ciphertext = aead.Seal(nil, result.Nonce, plaintext, nil)
+	return result, nil
+}
+
+func decryptSecrets(encrypted *encryptedSecrets, passphrase []byte) (*bundleSecrets, error) {
+	aead, err := bundleCipher(passphrase, encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, encrypted.Nonce, encrypted.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets; is the passphrase correct?")
+	}
+
+	var secrets bundleSecrets
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal secrets: %w", err)
+	}
+
+	return &secrets, nil
+}
+
+// Pack writes a portable bundle of the node to w: its metadata, config.hcl,
+// and storage, along with its logs if requested. The node is stopped while
+// its storage is archived and resumed afterwards.
+func (n *Node) Pack(w io.Writer, opts *BundleOptions) error {
+	if err := n.ValidateLocalStorage(); err != nil {
+		return fmt.Errorf("unable to pack: %w", err)
+	}
+
+	meta := &bundleMeta{
+		Node:      n.Name,
+		Directory: n.GetDirectory(),
+		Binary:    n.Binary,
+		Secrets:   opts.Secrets,
+	}
+
+	if version, err := n.BinaryVersion(); err == nil {
+		meta.Version = version
+	}
+
+	// Work on a copy of the node so that stripping secrets and execution
+	// state does not affect the running node.
+	packed, err := LoadNode(n.Name)
+	if err != nil {
+		return err
+	}
+
+	packed.Exec = nil
+	packed.Cluster = ""
+	packed.NonVoter = false
+
+	switch opts.Secrets {
+	case BundleSecretsInclude:
+	case BundleSecretsExclude, BundleSecretsEncrypt:
+		if opts.Secrets == BundleSecretsEncrypt {
+			meta.EncryptedSecrets, err = encryptSecrets(&bundleSecrets{Token: packed.Token, UnsealKeys: packed.UnsealKeys}, opts.Passphrase)
+			if err != nil {
+				return err
+			}
+		}
+
+		packed.Token = ""
+		packed.UnsealKeys = nil
+	default:
+		return fmt.Errorf("unknown secrets mode: `%v`; supported modes are %v", opts.Secrets, strings.Join(ListBundleSecretsModes(), ", "))
+	}
+
+	if opts.ExcludeTLS {
+		for _, listener := range packed.Config.Listeners {
+			if tcp, ok := listener.(*TCPListener); ok {
+				tcp.TLS = nil
+			}
+		}
+	}
+
+	nodeJson, err := json.Marshal(packed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal node: %w", err)
+	}
+
+	metaJson, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle metadata: %w", err)
+	}
+
+	config, err := packed.RenderConfig()
+	if err != nil {
+		return fmt.Errorf("failed to render configuration: %w", err)
+	}
+
+	entries := []string{nodeStorageDir}
+	if opts.Logs {
+		logs, err := filepath.Glob(filepath.Join(n.GetDirectory(), "*.log"))
+		if err != nil {
+			return fmt.Errorf("failed to list logs: %w", err)
+		}
+
+		for _, log := range logs {
+			entries = append(entries, filepath.Base(log))
+		}
+	}
+
+	return n.whileStopped(func() error {
+		archive := utils.NewTarGzWriter(w)
+		if err := archive.AddFile(BundleMetaName, metaJson, 0o644); err != nil {
+			return err
+		}
+
+		if err := archive.AddFile(NodeJsonName, nodeJson, 0o600); err != nil {
+			return err
+		}
+
+		if err := archive.AddFile(InstanceConfigName, []byte(config), 0o644); err != nil {
+			return err
+		}
+
+		if err := archive.AddTree(n.GetDirectory(), entries); err != nil {
+			return err
+		}
+
+		return archive.Close()
+	})
+}
+
+// UnpackNode creates a new node from the bundle read from r, named after
+// the packed node unless name is given. Paths in the node's configuration
+// are remapped to the new node directory, and bundles referencing paths
+// outside of it are refused. When the bundle's secrets are encrypted,
+// passphrase is called to decrypt them. Raft nodes are recovered as
+// single-node clusters. The node is saved but not started.
+func UnpackNode(r io.Reader, name string, passphrase func() ([]byte, error)) (*Node, []string, error) {
+	var warnings []string
+
+	parent := filepath.Dir(NodeBaseDirectory())
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create directory (%v): %w", parent, err)
+	}
+
+	staging, err := os.MkdirTemp(parent, ".unpack-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	defer os.RemoveAll(staging)
+
+	if err := utils.ExtractTarGz(r, staging); err != nil {
+		return nil, nil, fmt.Errorf("failed to extract bundle: %w", err)
+	}
+
+	metaPath := filepath.Join(staging, BundleMetaName)
+	metaJson, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bundle has no %v; is it a node bundle?", BundleMetaName)
+	}
+
+	var meta bundleMeta
+	if err := json.Unmarshal(metaJson, &meta); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal bundle metadata: %w", err)
+	}
+
+	if err := os.Remove(metaPath); err != nil {
+		return nil, nil, fmt.Errorf("failed to remove bundle metadata: %w", err)
+	}
+
+	nodeJson, err := os.ReadFile(filepath.Join(staging, NodeJsonName))
+	if err != nil {
+		return nil, nil, fmt.Errorf("bundle has no %v: %w", NodeJsonName, err)
+	}
+
+	var iface map[string]interface{}
+	if err := json.Unmarshal(nodeJson, &iface); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal node: %w", err)
+	}
+
+	var node Node
+	if err := node.FromInterface(iface); err != nil {
+		return nil, nil, fmt.Errorf("failed to translate node: %w", err)
+	}
+
+	if name != "" {
+		node.Name = name
+	}
+
+	if node.Name == "" || strings.ContainsRune(node.Name, os.PathSeparator) || strings.HasPrefix(node.Name, ".") {
+		return nil, nil, fmt.Errorf("invalid node name: `%v`", node.Name)
+	}
+
+	present, err := NodeExists(node.Name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error checking if node exists: %w", err)
+	}
+
+	if present {
+		return nil, nil, fmt.Errorf("node %v already exists; choose another name with --name", node.Name)
+	}
+
+	if meta.EncryptedSecrets != nil {
+		key, err := passphrase()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		secrets, err := decryptSecrets(meta.EncryptedSecrets, key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		node.Token = secrets.Token
+		node.UnsealKeys = secrets.UnsealKeys
+	} else if meta.Secrets == BundleSecretsExclude {
+		warnings = append(warnings, "bundle excludes the node's token and unseal keys; set them with `devbao node set-token` and `devbao node set-unseal`")
+	}
+
+	// Bundles are untrusted: only pins to registered binaries are kept, and
+	// the node is never run from the bundle's own command line.
+	if node.Binary != "" {
+		if registered, _ := BinaryExists(node.Binary); !registered {
+			warnings = append(warnings, fmt.Sprintf("node was pinned to binary %v (version %v), which is not a registered binary here; it will run the binary on the PATH instead", node.Binary, meta.Version))
+			node.PinBinary("")
+		}
+	}
+
+	node.Adopted = false
+	node.Exec = nil
+
+	// The packed node may have been a member of a cluster whose peers are
+	// recorded in its raft storage; recover it as a single-node cluster
+	// under its new name instead.
+	raft, isRaft := node.Config.Storage.(*RaftStorage)
+	if isRaft {
+		raft.NodeID = node.Name
+		raft.RetryJoin = nil
+		raft.RetryJoinAsNonVoter = false
+	}
+
+	// Remap the packed node's directory to its new location before moving
+	// the node into place.
+	directory := node.GetDirectory()
+	if err := confineBundleNode(&node, meta.Directory, directory); err != nil {
+		return nil, nil, err
+	}
+
+	if err := os.MkdirAll(NodeBaseDirectory(), 0o755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create node directory (%v): %w", NodeBaseDirectory(), err)
+	}
+
+	if err := os.Rename(staging, directory); err != nil {
+		return nil, nil, fmt.Errorf("failed to move node into place: %w", err)
+	}
+
+	if isRaft {
+		if err := node.writeRaftPeers(); err != nil {
+			_ = os.RemoveAll(directory)
+			return nil, nil, err
+		}
+	}
+
+	// The bundle's config.hcl is not trusted; render it anew from the
+	// checked node configuration.
+	config, err := node.RenderConfig()
+	if err == nil {
+		_, err = node.SaveInstanceConfig(config)
+	}
+	if err != nil {
+		_ = os.RemoveAll(directory)
+		return nil, nil, fmt.Errorf("failed to save node %v: %w", node.Name, err)
+	}
+
+	if err := node.SaveConfig(); err != nil {
+		return nil, nil, err
+	}
+
+	return &node, warnings, nil
+}
+
+// quotedString matches the double-quoted strings of raw HCL configuration.
+var quotedString = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+
+// confineBundlePath remaps a path under the packed node's directory (from)
+// to the new node directory (to), rejecting paths which would resolve
+// outside of the new node directory.
+func confineBundlePath(path string, from string, to string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	if from != "" && (path == from || strings.HasPrefix(path, from+string(os.PathSeparator))) {
+		path = to + strings.TrimPrefix(path, from)
+	}
+
+	resolved := path
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(to, resolved)
+	}
+
+	rel, err := filepath.Rel(to, filepath.Clean(resolved))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("bundle references path `%v` outside of the node directory", path)
+	}
+
+	return path, nil
+}
+
+// confineBundleNode remaps all paths of an unpacked node to its new
+// directory, failing if any points elsewhere.
+func confineBundleNode(node *Node, from string, to string) error {
+	var err error
+	confine := func(path *string) {
+		if err == nil {
+			*path, err = confineBundlePath(*path, from, to)
+		}
+	}
+
+	switch storage := node.Config.Storage.(type) {
+	case *RaftStorage:
+		confine(&storage.Path)
+	case *FileStorage:
+		confine(&storage.Path)
+	}
+
+	for _, listener := range node.Config.Listeners {
+		if unix, ok := listener.(*UnixListener); ok {
+			confine(&unix.Path)
+		}
+	}
+
+	for _, audit := range node.Config.Audits {
+		if file, ok := audit.(*FileAudit); ok {
+			confine(&file.FilePath)
+		}
+	}
+
+	confine(&node.Ca)
+
+	// Only quoted values which look like paths are checked, as raw
+	// configuration also holds addresses and other strings.
+	node.Config.RawConfig = quotedString.ReplaceAllStringFunc(node.Config.RawConfig, func(quoted string) string {
+		value := quoted[1 : len(quoted)-1]
+		if !filepath.IsAbs(value) && !strings.Contains(value, "..") {
+			return quoted
+		}
+
+		confined := value
+		confine(&confined)
+		return `"` + confined + `"`
+	})
+
+	return err
+}
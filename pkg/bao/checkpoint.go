@@ -0,0 +1,308 @@
+package bao
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openbao/devbao/pkg/utils"
+)
+
+const (
+	CheckpointDirName    = "checkpoints"
+	CheckpointFileSuffix = ".tar.gz"
+	CheckpointMetaSuffix = ".json"
+)
+
+// Checkpoint describes a filesystem-level archive of a stopped node's
+// storage along with its node.json and config.hcl.
+type Checkpoint struct {
+	Name    string    `json:"name"`
+	Time    time.Time `json:"time"`
+	Storage string    `json:"storage"`
+	Size    int64     `json:"size"`
+}
+
+func (n *Node) checkpointDirectory() string {
+	return filepath.Join(n.GetDirectory(), CheckpointDirName)
+}
+
+func (n *Node) checkpointPath(name string) string {
+	return filepath.Join(n.checkpointDirectory(), name+CheckpointFileSuffix)
+}
+
+func (n *Node) checkpointMetaPath(name string) string {
+	return filepath.Join(n.checkpointDirectory(), name+CheckpointMetaSuffix)
+}
+
+// ValidateLocalStorage ensures the node's storage lives on disk within
+// devbao's node directory, so that it can be archived or copied.
+func (n *Node) ValidateLocalStorage() error {
+	if n.Adopted {
+		return fmt.Errorf("node %v was adopted; its storage lives outside of devbao's node directory", n.Name)
+	}
+
+	if n.Config.Dev != nil {
+		return fmt.Errorf("node %v is a dev mode instance with in-memory storage", n.Name)
+	}
+
+	switch storage := n.Config.Storage.(type) {
+	case *RaftStorage:
+		if storage.Path != "" {
+			return fmt.Errorf("node %v uses a custom raft storage path (%v) outside of devbao's node directory", n.Name, storage.Path)
+		}
+	case *FileStorage:
+		if storage.Path != "" {
+			return fmt.Errorf("node %v uses a custom file storage path (%v) outside of devbao's node directory", n.Name, storage.Path)
+		}
+	default:
+		return fmt.Errorf("node %v does not use raft or file storage", n.Name)
+	}
+
+	return nil
+}
+
+// whileStopped runs op with the node stopped, resuming (and unsealing) it
+// afterwards if it was running beforehand, even when op fails.
+func (n *Node) whileStopped(op func() error) error {
+	running := n.Exec != nil && n.Exec.ValidateRunning() == nil
+	if running {
+		if err := n.Kill(); err != nil {
+			return fmt.Errorf("failed to stop node: %w", err)
+		}
+
+		if err := n.Exec.WaitStopped(); err != nil {
+			return fmt.Errorf("failed waiting for node to stop: %w", err)
+		}
+	}
+
+	opErr := op()
+	if !running {
+		return opErr
+	}
+
+	// The operation may have replaced the node's definition on disk.
+	if err := n.LoadConfig(); err != nil {
+		return errors.Join(opErr, err)
+	}
+
+	if err := n.resumeUnsealed(); err != nil {
+		return errors.Join(opErr, err)
+	}
+
+	return opErr
+}
+
+func (n *Node) ListCheckpoints() ([]*Checkpoint, error) {
+	directory := n.checkpointDirectory()
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error listing checkpoint directory (`%v`): %w", directory, err)
+	}
+
+	var results []*Checkpoint
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), CheckpointMetaSuffix)
+		if entry.IsDir() || !found {
+			continue
+		}
+
+		checkpoint, err := n.LoadCheckpoint(name)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, checkpoint)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Time.Before(results[j].Time)
+	})
+
+	return results, nil
+}
+
+func (n *Node) LoadCheckpoint(name string) (*Checkpoint, error) {
+	path := n.checkpointMetaPath(name)
+	metaFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint metadata (`%v`) for reading: %w", path, err)
+	}
+
+	defer metaFile.Close()
+
+	var checkpoint Checkpoint
+	if err := json.NewDecoder(metaFile).Decode(&checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint metadata: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
+// CreateCheckpoint stops the node, archives its storage, node.json, and
+// config.hcl under the given name (by default, the current time), and
+// resumes it if it was running.
+func (n *Node) CreateCheckpoint(name string) (*Checkpoint, error) {
+	if err := n.ValidateLocalStorage(); err != nil {
+		return nil, fmt.Errorf("unable to checkpoint: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if name == "" {
+		name = now.Format("20060102T150405Z")
+	}
+
+	if strings.ContainsRune(name, os.PathSeparator) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid checkpoint name: `%v`", name)
+	}
+
+	directory := n.checkpointDirectory()
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory (%v): %w", directory, err)
+	}
+
+	checkpoint := &Checkpoint{
+		Name:    name,
+		Time:    now,
+		Storage: n.Config.Storage.StorageType(),
+	}
+
+	err := n.whileStopped(func() error {
+		// Write to a temporary file first so that a failed checkpoint
+		// never replaces an existing one of the same name.
+		path := n.checkpointPath(name)
+		tmpPath := path + ".tmp"
+		archiveFile, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open checkpoint file (`%v`) for writing: %w", tmpPath, err)
+		}
+
+		defer os.Remove(tmpPath)
+		defer archiveFile.Close()
+
+		if err := utils.WriteTarGz(archiveFile, n.GetDirectory(), []string{NodeJsonName, InstanceConfigName, nodeStorageDir}); err != nil {
+			return fmt.Errorf("failed to archive node %v: %w", n.Name, err)
+		}
+
+		info, err := archiveFile.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat checkpoint file: %w", err)
+		}
+
+		checkpoint.Size = info.Size()
+
+		if err := os.Rename(tmpPath, path); err != nil {
+			return fmt.Errorf("failed to move checkpoint into place: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metaFile, err := os.OpenFile(n.checkpointMetaPath(name), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint metadata (`%v`) for writing: %w", n.checkpointMetaPath(name), err)
+	}
+
+	defer metaFile.Close()
+
+	if err := json.NewEncoder(metaFile).Encode(checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to marshal checkpoint metadata: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// RestoreCheckpoint stops the node and replaces its storage, node.json, and
+// config.hcl with those of the named checkpoint, resuming it if it was
+// running. The archive is extracted fully before anything is replaced, so
+// a corrupt checkpoint leaves the node untouched.
+func (n *Node) RestoreCheckpoint(name string) error {
+	if err := n.ValidateLocalStorage(); err != nil {
+		return fmt.Errorf("unable to restore checkpoint: %w", err)
+	}
+
+	if _, err := n.LoadCheckpoint(name); err != nil {
+		return err
+	}
+
+	return n.whileStopped(func() error {
+		directory := n.GetDirectory()
+		staging, err := os.MkdirTemp(directory, ".checkpoint-")
+		if err != nil {
+			return fmt.Errorf("failed to create staging directory: %w", err)
+		}
+
+		defer os.RemoveAll(staging)
+
+		path := n.checkpointPath(name)
+		archiveFile, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open checkpoint file (`%v`) for reading: %w", path, err)
+		}
+
+		defer archiveFile.Close()
+
+		if err := utils.ExtractTarGz(archiveFile, staging); err != nil {
+			return fmt.Errorf("failed to extract checkpoint %v: %w", name, err)
+		}
+
+		if _, err := os.Stat(filepath.Join(staging, NodeJsonName)); err != nil {
+			return fmt.Errorf("checkpoint %v has no %v", name, NodeJsonName)
+		}
+
+		// Move the current storage, config.hcl, and node.json aside, outside
+		// of staging, so that they can be put back if swapping in the
+		// checkpoint's fails.
+		previous, err := os.MkdirTemp(directory, ".previous-")
+		if err != nil {
+			return fmt.Errorf("failed to create directory for current state: %w", err)
+		}
+
+		entries := []string{nodeStorageDir, InstanceConfigName, NodeJsonName}
+		var moved []string
+		rollback := func(cause error) error {
+			for index := len(moved) - 1; index >= 0; index-- {
+				entry := moved[index]
+				if err := os.RemoveAll(filepath.Join(directory, entry)); err != nil {
+					return fmt.Errorf("%w; rolling back failed, previous state is kept in %v: %v", cause, previous, err)
+				}
+
+				if err := os.Rename(filepath.Join(previous, entry), filepath.Join(directory, entry)); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("%w; rolling back failed, previous state is kept in %v: %v", cause, previous, err)
+				}
+			}
+
+			_ = os.RemoveAll(previous)
+			return cause
+		}
+
+		for _, entry := range entries {
+			if _, err := os.Stat(filepath.Join(staging, entry)); os.IsNotExist(err) {
+				continue
+			}
+
+			if err := os.Rename(filepath.Join(directory, entry), filepath.Join(previous, entry)); err != nil && !os.IsNotExist(err) {
+				return rollback(fmt.Errorf("failed to move current %v aside: %w", entry, err))
+			}
+
+			moved = append(moved, entry)
+			if err := os.Rename(filepath.Join(staging, entry), filepath.Join(directory, entry)); err != nil {
+				return rollback(fmt.Errorf("failed to move checkpoint %v into place: %w", entry, err))
+			}
+		}
+
+		return os.RemoveAll(previous)
+	})
+}
@@ -0,0 +1,222 @@
+package bao
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/openbao/devbao/pkg/utils"
+)
+
+const (
+	nodeStorageDir = "storage"
+	raftPeersName  = "peers.json"
+)
+
+// usedPorts collects the TCP ports claimed by every node's listeners,
+// running or not, along with the cluster and proxy ports derived from them.
+func usedPorts() (map[int]bool, error) {
+	nodes, err := ListNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[int]bool{}
+	for _, name := range nodes {
+		node, err := LoadNodeUnvalidated(name)
+		if err != nil {
+			continue
+		}
+
+		for _, listener := range node.Config.Listeners {
+			if tcp, ok := listener.(*TCPListener); ok {
+				if _, port, err := tcpPort(tcp); err == nil {
+					used[port] = true
+					used[port+1] = true
+					used[port+2] = true
+				}
+			}
+		}
+	}
+
+	return used, nil
+}
+
+func portAvailable(host string, port int, used map[int]bool) bool {
+	if used[port] {
+		return false
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+
+	listener.Close()
+	return true
+}
+
+// allocatePorts moves every TCP listener in the configuration by the same
+// offset, in steps of 100, to the first block where each listener's port
+// and the cluster port above it are unclaimed by any node and free to bind.
+func allocatePorts(cfg *NodeConfig) error {
+	used, err := usedPorts()
+	if err != nil {
+		return err
+	}
+
+	var listeners []*TCPListener
+	for _, listener := range cfg.Listeners {
+		if tcp, ok := listener.(*TCPListener); ok {
+			listeners = append(listeners, tcp)
+		}
+	}
+
+	for offset := 100; offset < 65535; offset += 100 {
+		available := true
+		for _, tcp := range listeners {
+			host, port, err := tcpPort(tcp)
+			if err != nil {
+				return err
+			}
+
+			if port+offset+1 > 65535 || !portAvailable(host, port+offset, used) || !portAvailable(host, port+offset+1, used) {
+				available = false
+				break
+			}
+		}
+
+		if !available {
+			continue
+		}
+
+		for _, tcp := range listeners {
+			host, port, _ := tcpPort(tcp)
+			tcp.Address = net.JoinHostPort(host, strconv.Itoa(port+offset))
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unable to find free ports for the node's listeners")
+}
+
+// Clone copies the node, including its storage, unseal keys, and token,
+// into a new, independent node named dst. Listeners move to free ports and
+// any cluster membership is stripped: a raft node is recovered as the sole
+// member of its own cluster via peers.json on first start. The source node
+// is stopped while its storage is copied and resumed afterwards; the clone
+// is saved but not started.
+func (n *Node) Clone(dst string) (*Node, error) {
+	if err := n.ValidateLocalStorage(); err != nil {
+		return nil, fmt.Errorf("unable to clone: %w", err)
+	}
+
+	present, err := NodeExists(dst)
+	if err != nil {
+		return nil, fmt.Errorf("error checking if node exists: %w", err)
+	}
+
+	if present {
+		return nil, fmt.Errorf("node %v already exists", dst)
+	}
+
+	cfg, err := n.Config.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy configuration of node %v: %w", n.Name, err)
+	}
+
+	cfg.ClusterProxy = false
+	if err := allocatePorts(cfg); err != nil {
+		return nil, err
+	}
+
+	raft, isRaft := cfg.Storage.(*RaftStorage)
+	if isRaft {
+		raft.NodeID = dst
+		raft.RetryJoin = nil
+		raft.RetryJoinAsNonVoter = false
+	}
+
+	clone := &Node{
+		Name:       dst,
+		Type:       n.Type,
+		Config:     *cfg,
+		Token:      n.Token,
+		UnsealKeys: n.UnsealKeys,
+		Binary:     n.Binary,
+	}
+
+	directory := clone.GetDirectory()
+	err = n.whileStopped(func() error {
+		if err := os.MkdirAll(directory, 0o755); err != nil {
+			return fmt.Errorf("failed to create node directory (%v): %w", directory, err)
+		}
+
+		return utils.CopyDir(filepath.Join(n.GetDirectory(), nodeStorageDir), filepath.Join(directory, nodeStorageDir))
+	})
+	if err != nil {
+		_ = os.RemoveAll(directory)
+		return nil, fmt.Errorf("failed to copy storage of node %v: %w", n.Name, err)
+	}
+
+	if isRaft {
+		if err := clone.writeRaftPeers(); err != nil {
+			_ = os.RemoveAll(directory)
+			return nil, err
+		}
+	}
+
+	config, err := clone.RenderConfig()
+	if err == nil {
+		_, err = clone.SaveInstanceConfig(config)
+	}
+	if err == nil {
+		err = clone.SaveConfig()
+	}
+	if err != nil {
+		_ = os.RemoveAll(directory)
+		return nil, fmt.Errorf("failed to save node %v: %w", dst, err)
+	}
+
+	return clone, nil
+}
+
+// writeRaftPeers writes a raft peers.json naming the node as the only
+// voter, so that it recovers from its copied storage under its new node ID
+// and cluster address.
+func (n *Node) writeRaftPeers() error {
+	directory := n.GetDirectory()
+	_, advertise, err := n.Config.ClusterAddrs(directory)
+	if err != nil {
+		return err
+	}
+
+	peers := []map[string]interface{}{
+		{
+			"id":        n.Name,
+			"address":   advertise,
+			"non_voter": false,
+		},
+	}
+
+	data, err := json.Marshal(peers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal raft peers: %w", err)
+	}
+
+	raftDir := filepath.Join(directory, nodeStorageDir, "raft", "raft")
+	if err := os.MkdirAll(raftDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create raft directory (%v): %w", raftDir, err)
+	}
+
+	path := filepath.Join(raftDir, raftPeersName)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write raft peers (%v): %w", path, err)
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
@@ -26,6 +27,19 @@ type Cluster struct {
 	Type string `json:"type"`
 
 	Nodes []string `json:"nodes"`
+
+	// RetryJoin clusters are formed through raft retry_join stanzas on
+	// every member, rather than explicit join requests against the leader.
+	RetryJoin bool `json:"retry_join,omitempty"`
+
+	// Proxy is the execution state of the cluster's fault-injection proxy,
+	// through which members' raft traffic flows; clusters started without
+	// one leave it unset.
+	Proxy *ExecEnvironment `json:"proxy,omitempty"`
+
+	// Profiles are those applied when the cluster was started, letting
+	// `up` tell whether the cluster still matches its spec.
+	Profiles []string `json:"profiles,omitempty"`
 }
 
 func BuildHACluster(clusterName string, nodeName string) (*Cluster, error) {
@@ -180,6 +194,28 @@ func (c *Cluster) FromInterface(iface map[string]interface{}) error {
 		}
 	}
 
+	if retryJoin, ok := iface["retry_join"].(bool); ok {
+		c.RetryJoin = retryJoin
+	}
+
+	if profilesRaw, ok := iface["profiles"].([]interface{}); ok {
+		c.Profiles = nil
+		for _, profileRaw := range profilesRaw {
+			c.Profiles = append(c.Profiles, profileRaw.(string))
+		}
+	}
+
+	if data, present := iface["proxy"]; present && data != nil {
+		j, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("error re-marshalling proxy exec config: %w", err)
+		}
+
+		if err := json.Unmarshal(j, &c.Proxy); err != nil {
+			return fmt.Errorf("error unmarshaling proxy exec config; %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -252,6 +288,112 @@ func (c *Cluster) GetLeader() (*Node, *api.Client, error) {
 	return nil, nil, err
 }
 
+// WaitForLeader polls the cluster with increasing back-off until a leader
+// is elected.
+func (c *Cluster) WaitForLeader() (*Node, *api.Client, error) {
+	leaderNode, leaderClient, err := c.GetLeader()
+	errCount := 0
+	for err != nil {
+		errCount += 1
+		if errCount > 5 {
+			return nil, nil, fmt.Errorf("failed to find cluster leader: %w", err)
+		}
+
+		time.Sleep(time.Duration(errCount) * time.Second)
+		leaderNode, leaderClient, err = c.GetLeader()
+	}
+
+	return leaderNode, leaderClient, nil
+}
+
+// ClusterNodeResult is the outcome of an operation on a single member.
+type ClusterNodeResult struct {
+	Name    string
+	Message string
+	Err     error
+}
+
+// LoadMembers loads all members of the cluster, split into voters and
+// non-voters.
+func (c *Cluster) LoadMembers() ([]*Node, []*Node, error) {
+	var voters []*Node
+	var nonVoters []*Node
+	for index, name := range c.Nodes {
+		node, err := LoadNode(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error loading node %d / %v: %w", index, name, err)
+		}
+
+		if node.NonVoter {
+			nonVoters = append(nonVoters, node)
+		} else {
+			voters = append(voters, node)
+		}
+	}
+
+	return voters, nonVoters, nil
+}
+
+// ForEachNode runs op on every given node concurrently, returning the
+// results in the same order as the nodes.
+func ForEachNode(nodes []*Node, op func(node *Node) (string, error)) []*ClusterNodeResult {
+	results := make([]*ClusterNodeResult, len(nodes))
+
+	var wg sync.WaitGroup
+	for index, node := range nodes {
+		wg.Add(1)
+		go func(index int, node *Node) {
+			defer wg.Done()
+
+			message, err := op(node)
+			results[index] = &ClusterNodeResult{
+				Name:    node.Name,
+				Message: message,
+				Err:     err,
+			}
+		}(index, node)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// GetAutopilotConfig reads the raft autopilot configuration through the
+// cluster's leader.
+func (c *Cluster) GetAutopilotConfig() (*api.AutopilotConfig, error) {
+	_, leaderClient, err := c.GetLeader()
+	if err != nil {
+		return nil, fmt.Errorf("error finding leader: %w", err)
+	}
+
+	config, err := leaderClient.Sys().RaftAutopilotConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("error reading autopilot configuration: %w", err)
+	}
+
+	if config == nil {
+		return nil, fmt.Errorf("empty autopilot configuration; is the cluster using raft storage")
+	}
+
+	return config, nil
+}
+
+// SetAutopilotConfig updates only the given fields of the raft autopilot
+// configuration, keyed by their API names.
+func (c *Cluster) SetAutopilotConfig(fields map[string]interface{}) error {
+	_, leaderClient, err := c.GetLeader()
+	if err != nil {
+		return fmt.Errorf("error finding leader: %w", err)
+	}
+
+	if _, err := leaderClient.Logical().Write("sys/storage/raft/autopilot/configuration", fields); err != nil {
+		return fmt.Errorf("error updating autopilot configuration: %w", err)
+	}
+
+	return nil
+}
+
 func (c *Cluster) JoinNodeHACluster(node *Node) error {
 	leaderNode, leaderClient, err := c.GetLeader()
 	if err != nil {
@@ -317,18 +459,186 @@ func (c *Cluster) JoinNodeHACluster(node *Node) error {
 		return fmt.Errorf("failed saving updated cluster state: %w", err)
 	}
 
+	if c.RetryJoin {
+		return c.UpdateRetryJoin()
+	}
+
 	return nil
 }
 
+// RegisterNodeHACluster records the node as a member of the cluster without
+// contacting the leader; on start, the node is expected to join the cluster
+// itself through its retry_join configuration.
+func (c *Cluster) RegisterNodeHACluster(node *Node) error {
+	if node.Cluster != "" && node.Cluster != c.Name {
+		return fmt.Errorf("node `%v` already present in different cluster: `%v`", node.Name, node.Cluster)
+	}
+
+	if _, ok := node.Config.Storage.(*RaftStorage); !ok {
+		return fmt.Errorf("node `%v` does not use raft storage", node.Name)
+	}
+
+	node.Cluster = c.Name
+	if err := node.SaveConfig(); err != nil {
+		return fmt.Errorf("failed saving node `%v` while adding to cluster: %w", node.Name, err)
+	}
+
+	c.Nodes = append(c.Nodes, node.Name)
+	if err := c.SaveConfig(); err != nil {
+		return fmt.Errorf("failed saving updated cluster state: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRetryJoin rewrites the raft storage of every member so that it
+// retry_joins all other members. Running nodes pick up the change on their
+// next restart.
+func (c *Cluster) UpdateRetryJoin() error {
+	nodes, peers, err := c.retryJoinPeers()
+	if err != nil {
+		return err
+	}
+
+	for index, node := range nodes {
+		storage, ok := node.Config.Storage.(*RaftStorage)
+		if !ok {
+			return fmt.Errorf("node %v does not use raft storage", node.Name)
+		}
+
+		storage.RetryJoin = nil
+		for peerIndex, peer := range peers {
+			if peerIndex != index {
+				storage.RetryJoin = append(storage.RetryJoin, peer)
+			}
+		}
+
+		storage.RetryJoinAsNonVoter = node.NonVoter
+
+		if err := node.SaveConfig(); err != nil {
+			return fmt.Errorf("failed saving retry_join configuration for node %v: %w", node.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// retryJoinPeers loads every member along with the retry_join stanza other
+// nodes use to reach it.
+func (c *Cluster) retryJoinPeers() ([]*Node, []*RaftRetryJoin, error) {
+	var nodes []*Node
+	var peers []*RaftRetryJoin
+	for index, name := range c.Nodes {
+		node, err := LoadNode(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error loading node %d / %v: %w", index, name, err)
+		}
+
+		peer, err := node.RetryJoinPeer()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		nodes = append(nodes, node)
+		peers = append(peers, peer)
+	}
+
+	return nodes, peers, nil
+}
+
+// RetryJoinPeer returns the retry_join stanza other members use to reach
+// the node, with the scheme and CA certificate of its listener.
+func (n *Node) RetryJoinPeer() (*RaftRetryJoin, error) {
+	addr, ca, err := n.GetConnectAddr()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RaftRetryJoin{
+		LeaderAPIAddr:    addr,
+		LeaderCACertFile: ca,
+	}, nil
+}
+
 func (c *Cluster) RemoveNodeHACluster(node *Node) error {
 	_, leaderClient, err := c.GetLeader()
 	if err != nil {
 		return fmt.Errorf("error finding leader: %w", err)
 	}
 
+	var raftId string
+	if storage, ok := node.Config.Storage.(*RaftStorage); ok && storage.NodeID != "" {
+		present, err := raftServerPresent(leaderClient, storage.NodeID)
+		if err != nil {
+			return fmt.Errorf("error reading raft configuration from node %v: %w", node.Name, err)
+		}
+
+		if present {
+			raftId = storage.NodeID
+		}
+	} else {
+		raftId, err = inferRaftID(leaderClient, node)
+		if err != nil {
+			return err
+		}
+	}
+
+	if raftId == "" {
+		// This node might've manually been removed from the cluster or never
+		// really joined to it.
+		return c.forgetNode(node.Name)
+	}
+
+	_, err = leaderClient.Logical().Write("sys/storage/raft/remove-peer", map[string]interface{}{
+		"server_id": raftId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed removing node %v from cluster: %w", node.Name, err)
+	}
+
+	node.Cluster = ""
+	if storage, ok := node.Config.Storage.(*RaftStorage); ok && c.RetryJoin {
+		// Otherwise the node would rejoin its former peers on restart.
+		storage.RetryJoin = nil
+		storage.RetryJoinAsNonVoter = false
+	}
+
+	// Outside the cluster, nothing proxies the node's cluster address.
+	node.Config.ClusterProxy = false
+
+	if err := node.SaveConfig(); err != nil {
+		return fmt.Errorf("failed saving updated state for joined node %v: %w", node.Name, err)
+	}
+
+	return c.forgetNode(node.Name)
+}
+
+// raftServerPresent checks whether a server with the given ID is a member
+// of the raft configuration.
+func raftServerPresent(client *api.Client, raftId string) (bool, error) {
+	cfgResp, err := client.Logical().Read("sys/storage/raft/configuration")
+	if err != nil {
+		return false, err
+	}
+
+	cfg := cfgResp.Data["config"].(map[string]interface{})
+	servers := cfg["servers"].([]interface{})
+	for _, serverRaw := range servers {
+		server := serverRaw.(map[string]interface{})
+		if server["node_id"].(string) == raftId {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// inferRaftID finds the raft ID of a node without an explicit node_id,
+// returning an empty ID if the node is not a member of the cluster.
+func inferRaftID(leaderClient *api.Client, node *Node) (string, error) {
 	nodeAddr, _, err := node.GetConnectAddr()
 	if err != nil {
-		return fmt.Errorf("failed to get node's address: %w", err)
+		return "", fmt.Errorf("failed to get node's address: %w", err)
 	}
 
 	// Inferring the node_id from the API address is difficult; we need to
@@ -336,7 +646,7 @@ func (c *Cluster) RemoveNodeHACluster(node *Node) error {
 	// and then find the server with the given cluster address.
 	statusResp, err := leaderClient.Logical().Read("sys/ha-status")
 	if err != nil {
-		return fmt.Errorf("error reading raft configuration from node %v: %w", node.Name, err)
+		return "", fmt.Errorf("error reading raft configuration from node %v: %w", node.Name, err)
 	}
 
 	clusterAddr := ""
@@ -351,33 +661,12 @@ func (c *Cluster) RemoveNodeHACluster(node *Node) error {
 	}
 
 	if clusterAddr == "" {
-		// This node might've manually been removed from the cluster or never
-		// really joined to it.
-		nodeIndex := -1
-		for index, name := range c.Nodes {
-			if name == node.Name {
-				nodeIndex = index
-				break
-			}
-		}
-
-		if nodeIndex != -1 {
-			nodesBefore := c.Nodes[0:nodeIndex]
-			nodesAfter := c.Nodes[nodeIndex+1:]
-			c.Nodes = append(nodesBefore, nodesAfter...)
-
-			if err := c.SaveConfig(); err != nil {
-				return fmt.Errorf("failed to save cluster %v after node removal: %w", c.Name, err)
-			}
-		}
-
-		return nil
-
+		return "", nil
 	}
 
 	cfgResp, err := leaderClient.Logical().Read("sys/storage/raft/configuration")
 	if err != nil {
-		return fmt.Errorf("error reading raft configuration from node %v: %w", node.Name, err)
+		return "", fmt.Errorf("error reading raft configuration from node %v: %w", node.Name, err)
 	}
 
 	raftId := ""
@@ -393,37 +682,36 @@ func (c *Cluster) RemoveNodeHACluster(node *Node) error {
 	}
 
 	if raftId == "" {
-		return fmt.Errorf("could not find node %v's raft ID based on sys/storage/raft/configuration response", node.Name)
-	}
-
-	_, err = leaderClient.Logical().Write("sys/storage/raft/remove-peer", map[string]interface{}{
-		"server_id": raftId,
-	})
-	if err != nil {
-		return fmt.Errorf("failed removing node %v from cluster: %w", node.Name, err)
+		return "", fmt.Errorf("could not find node %v's raft ID based on sys/storage/raft/configuration response", node.Name)
 	}
 
-	node.Cluster = ""
-	if err := node.SaveConfig(); err != nil {
-		return fmt.Errorf("failed saving updated state for joined node %v: %w", node.Name, err)
-	}
+	return raftId, nil
+}
 
+// forgetNode drops the named node from the cluster's membership list.
+func (c *Cluster) forgetNode(name string) error {
 	nodeIndex := -1
-	for index, name := range c.Nodes {
-		if name == node.Name {
+	for index, member := range c.Nodes {
+		if member == name {
 			nodeIndex = index
 			break
 		}
 	}
 
-	if nodeIndex != -1 {
-		nodesBefore := c.Nodes[0:nodeIndex]
-		nodesAfter := c.Nodes[nodeIndex+1:]
-		c.Nodes = append(nodesBefore, nodesAfter...)
+	if nodeIndex == -1 {
+		return nil
+	}
+
+	nodesBefore := c.Nodes[0:nodeIndex]
+	nodesAfter := c.Nodes[nodeIndex+1:]
+	c.Nodes = append(nodesBefore, nodesAfter...)
 
-		if err := c.SaveConfig(); err != nil {
-			return fmt.Errorf("failed to save cluster %v after node removal: %w", c.Name, err)
-		}
+	if err := c.SaveConfig(); err != nil {
+		return fmt.Errorf("failed to save cluster %v after node removal: %w", c.Name, err)
+	}
+
+	if c.RetryJoin {
+		return c.UpdateRetryJoin()
 	}
 
 	return nil
@@ -466,6 +754,10 @@ func (c *Cluster) Clean(force bool) error {
 		}
 	}
 
+	if err := c.StopProxy(); err != nil && !force {
+		return err
+	}
+
 	directory := c.GetDirectory()
 	return os.RemoveAll(directory)
 }
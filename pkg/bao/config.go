@@ -2,6 +2,7 @@ package bao
 
 import (
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"io"
@@ -104,6 +105,11 @@ func (t *TLSConfig) GetCA() (*x509.Certificate, string, error) {
 type TCPListener struct {
 	Address string     `json:"address"`
 	TLS     *TLSConfig `json:"tls,omitempty"`
+
+	// TLSFiles points the listener at the persisted certificate and key.
+	// Only imported listeners set it, leaving the configuration of other
+	// nodes unchanged.
+	TLSFiles bool `json:"tls_files,omitempty"`
 }
 
 func (t *TCPListener) FromInterface(iface map[string]interface{}) error {
@@ -115,6 +121,9 @@ func (t *TCPListener) FromInterface(iface map[string]interface{}) error {
 		}
 		t.TLS.Key = data["key"].(string)
 	}
+	if tlsFiles, ok := iface["tls_files"].(bool); ok {
+		t.TLSFiles = tlsFiles
+	}
 	return nil
 }
 
@@ -134,6 +143,11 @@ func (t *TCPListener) ToConfig(directory string) (string, error) {
 		if err := t.TLS.Write(caPath, certPath, keyPath); err != nil {
 			return "", fmt.Errorf("failed to persist TLS configuration: %w", err)
 		}
+
+		if t.TLSFiles {
+			config += `  tls_cert_file = "` + certPath + `"` + "\n"
+			config += `  tls_key_file = "` + keyPath + `"` + "\n"
+		}
 	}
 
 	config += "}\n"
@@ -199,17 +213,137 @@ var (
 	_ Listener = &UnixListener{}
 )
 
-type RaftStorage struct{}
+// RaftRetryJoin describes a peer which a raft node attempts to join on
+// start until it becomes a member of the cluster.
+type RaftRetryJoin struct {
+	LeaderAPIAddr        string `json:"leader_api_addr"`
+	LeaderTLSServerName  string `json:"leader_tls_servername,omitempty"`
+	LeaderCACertFile     string `json:"leader_ca_cert_file,omitempty"`
+	LeaderClientCertFile string `json:"leader_client_cert_file,omitempty"`
+	LeaderClientKeyFile  string `json:"leader_client_key_file,omitempty"`
+}
+
+func (r *RaftRetryJoin) FromInterface(iface map[string]interface{}) error {
+	r.LeaderAPIAddr, _ = iface["leader_api_addr"].(string)
+	r.LeaderTLSServerName, _ = iface["leader_tls_servername"].(string)
+	r.LeaderCACertFile, _ = iface["leader_ca_cert_file"].(string)
+	r.LeaderClientCertFile, _ = iface["leader_client_cert_file"].(string)
+	r.LeaderClientKeyFile, _ = iface["leader_client_key_file"].(string)
+	return nil
+}
+
+func (r *RaftRetryJoin) ToConfig(directory string) (string, error) {
+	config := "  retry_join {\n"
+	config += `    leader_api_addr = "` + r.LeaderAPIAddr + `"` + "\n"
+	if r.LeaderTLSServerName != "" {
+		config += `    leader_tls_servername = "` + r.LeaderTLSServerName + `"` + "\n"
+	}
+	if r.LeaderCACertFile != "" {
+		config += `    leader_ca_cert_file = "` + r.LeaderCACertFile + `"` + "\n"
+	}
+	if r.LeaderClientCertFile != "" {
+		config += `    leader_client_cert_file = "` + r.LeaderClientCertFile + `"` + "\n"
+	}
+	if r.LeaderClientKeyFile != "" {
+		config += `    leader_client_key_file = "` + r.LeaderClientKeyFile + `"` + "\n"
+	}
+	config += "  }\n"
+	return config, nil
+}
+
+type RaftStorage struct {
+	Path string `json:"path,omitempty"`
+
+	// NodeID fixes the node's raft server ID, rather than letting the
+	// server generate a random one on first start.
+	NodeID                string `json:"node_id,omitempty"`
+	PerformanceMultiplier int    `json:"performance_multiplier,omitempty"`
+	SnapshotThreshold     int    `json:"snapshot_threshold,omitempty"`
+	TrailingLogs          int    `json:"trailing_logs,omitempty"`
+	MaxEntrySize          int    `json:"max_entry_size,omitempty"`
+
+	// RetryJoin lists peers to join automatically on start, letting
+	// clusters self-assemble without explicit join requests.
+	RetryJoin           []*RaftRetryJoin `json:"retry_join,omitempty"`
+	RetryJoinAsNonVoter bool             `json:"retry_join_as_non_voter,omitempty"`
+}
 
 func (r *RaftStorage) FromInterface(iface map[string]interface{}) error {
+	if path, present := iface["path"]; present {
+		r.Path = path.(string)
+	}
+
+	r.NodeID, _ = iface["node_id"].(string)
+
+	if value, ok := iface["performance_multiplier"].(float64); ok {
+		r.PerformanceMultiplier = int(value)
+	}
+
+	if value, ok := iface["snapshot_threshold"].(float64); ok {
+		r.SnapshotThreshold = int(value)
+	}
+
+	if value, ok := iface["trailing_logs"].(float64); ok {
+		r.TrailingLogs = int(value)
+	}
+
+	if value, ok := iface["max_entry_size"].(float64); ok {
+		r.MaxEntrySize = int(value)
+	}
+
+	if retryJoinRaw, ok := iface["retry_join"].([]interface{}); ok {
+		r.RetryJoin = nil
+		for index, entryRaw := range retryJoinRaw {
+			entry := &RaftRetryJoin{}
+			if err := entry.FromInterface(entryRaw.(map[string]interface{})); err != nil {
+				return fmt.Errorf("failed to load retry_join %d: %w", index, err)
+			}
+
+			r.RetryJoin = append(r.RetryJoin, entry)
+		}
+	}
+
+	if nonVoter, ok := iface["retry_join_as_non_voter"].(bool); ok {
+		r.RetryJoinAsNonVoter = nonVoter
+	}
+
 	return nil
 }
 
 func (r *RaftStorage) ToConfig(directory string) (string, error) {
 	path := filepath.Join(directory, "storage/raft")
+	if r.Path != "" {
+		path = r.Path
+	}
 
 	config := `storage "raft" {` + "\n"
 	config += `  path = "` + path + `"` + "\n"
+	if r.NodeID != "" {
+		config += `  node_id = "` + r.NodeID + `"` + "\n"
+	}
+	if r.PerformanceMultiplier != 0 {
+		config += fmt.Sprintf("  performance_multiplier = %d\n", r.PerformanceMultiplier)
+	}
+	if r.SnapshotThreshold != 0 {
+		config += fmt.Sprintf("  snapshot_threshold = %d\n", r.SnapshotThreshold)
+	}
+	if r.TrailingLogs != 0 {
+		config += fmt.Sprintf("  trailing_logs = %d\n", r.TrailingLogs)
+	}
+	if r.MaxEntrySize != 0 {
+		config += fmt.Sprintf("  max_entry_size = %d\n", r.MaxEntrySize)
+	}
+	if r.RetryJoinAsNonVoter {
+		config += `  retry_join_as_non_voter = true` + "\n"
+	}
+	for index, entry := range r.RetryJoin {
+		entryConfig, err := entry.ToConfig(directory)
+		if err != nil {
+			return "", fmt.Errorf("failed to build retry_join %d: %w", index, err)
+		}
+
+		config += entryConfig
+	}
 	config += "}\n"
 
 	if err := os.MkdirAll(path, 0o755); err != nil {
@@ -223,14 +357,22 @@ func (r *RaftStorage) StorageType() string {
 	return "raft"
 }
 
-type FileStorage struct{}
+type FileStorage struct {
+	Path string `json:"path,omitempty"`
+}
 
 func (f *FileStorage) FromInterface(iface map[string]interface{}) error {
+	if path, present := iface["path"]; present {
+		f.Path = path.(string)
+	}
 	return nil
 }
 
 func (f *FileStorage) ToConfig(directory string) (string, error) {
 	path := filepath.Join(directory, "storage/file")
+	if f.Path != "" {
+		path = f.Path
+	}
 	if err := os.MkdirAll(path, 0o755); err != nil {
 		return "", fmt.Errorf("failed to make file storage directory (%v): %w", path, err)
 	}
@@ -354,7 +496,7 @@ func (f *FileAudit) ToConfig(directory string) (string, error) {
 	return "", nil
 }
 
-func (f *FileAudit) PostUnseal(client *api.Client, directory string) error {
+func (f *FileAudit) DeviceName() string {
 	name := "audit"
 	if f.FilePath != "" {
 		name += "-custom"
@@ -366,6 +508,12 @@ func (f *FileAudit) PostUnseal(client *api.Client, directory string) error {
 		name += "-raw"
 	}
 
+	return name
+}
+
+func (f *FileAudit) PostUnseal(client *api.Client, directory string) error {
+	name := f.DeviceName()
+
 	filePath := filepath.Join(directory, name)
 	filePath += ".log"
 
@@ -394,7 +542,9 @@ func (f *FileAudit) PostUnseal(client *api.Client, directory string) error {
 		return fmt.Errorf("failed to list audit devices; %w", err)
 	}
 
-	if _, present := resp.Data[name]; !present {
+	_, present := resp.Data[name]
+	_, presentDir := resp.Data[name+"/"]
+	if !present && !presentDir {
 		data := map[string]interface{}{
 			"type":    "file",
 			"options": opts,
@@ -421,6 +571,9 @@ type Audit interface {
 	PostUnsealHook
 
 	GetAudit() (string, error)
+
+	// DeviceName is the path under sys/audit the device is enabled at.
+	DeviceName() string
 }
 
 var _ Audit = &FileAudit{}
@@ -443,6 +596,56 @@ func (u *UI) PostUnseal(client *api.Client, directory string) error {
 	return nil
 }
 
+type Telemetry struct {
+	PrometheusRetentionTime string `json:"prometheus_retention_time,omitempty"`
+	DisableHostname         bool   `json:"disable_hostname,omitempty"`
+	StatsdAddress           string `json:"statsd_address,omitempty"`
+	StatsiteAddress         string `json:"statsite_address,omitempty"`
+	UsageGaugePeriod        string `json:"usage_gauge_period,omitempty"`
+}
+
+func (t *Telemetry) FromInterface(iface map[string]interface{}) error {
+	if value, present := iface["prometheus_retention_time"]; present {
+		t.PrometheusRetentionTime = value.(string)
+	}
+	if value, present := iface["disable_hostname"]; present {
+		t.DisableHostname = value.(bool)
+	}
+	if value, present := iface["statsd_address"]; present {
+		t.StatsdAddress = value.(string)
+	}
+	if value, present := iface["statsite_address"]; present {
+		t.StatsiteAddress = value.(string)
+	}
+	if value, present := iface["usage_gauge_period"]; present {
+		t.UsageGaugePeriod = value.(string)
+	}
+	return nil
+}
+
+func (t *Telemetry) ToConfig(directory string) (string, error) {
+	config := "telemetry {\n"
+	if t.PrometheusRetentionTime != "" {
+		config += `  prometheus_retention_time = "` + t.PrometheusRetentionTime + `"` + "\n"
+	}
+	if t.DisableHostname {
+		config += "  disable_hostname = true\n"
+	}
+	if t.StatsdAddress != "" {
+		config += `  statsd_address = "` + t.StatsdAddress + `"` + "\n"
+	}
+	if t.StatsiteAddress != "" {
+		config += `  statsite_address = "` + t.StatsiteAddress + `"` + "\n"
+	}
+	if t.UsageGaugePeriod != "" {
+		config += `  usage_gauge_period = "` + t.UsageGaugePeriod + `"` + "\n"
+	}
+	config += "}\n"
+	return config, nil
+}
+
+var _ ConfigBuilder = &Telemetry{}
+
 type NodeConfig struct {
 	Dev           *DevConfig `json:"dev,omitempty"`
 	ListenerTypes []string   `json:"listener_types,omitempty"`
@@ -454,6 +657,17 @@ type NodeConfig struct {
 	AuditTypes    []string   `json:"audit_types,omitempty"`
 	Audits        []Audit    `json:"audits,omitempty"`
 	UI            *UI        `json:"ui,omitempty"`
+	LogLevel      string     `json:"log_level,omitempty"`
+	Telemetry     *Telemetry `json:"telemetry,omitempty"`
+
+	// RawConfig holds verbatim HCL stanzas which devbao does not model,
+	// such as those preserved when importing an existing configuration.
+	RawConfig string `json:"raw_config,omitempty"`
+
+	// ClusterProxy advertises a cluster_addr on which the cluster's
+	// fault-injection proxy listens rather than the cluster listener
+	// itself.
+	ClusterProxy bool `json:"cluster_proxy,omitempty"`
 }
 
 func (n *NodeConfig) FromInterface(iface map[string]interface{}) error {
@@ -566,6 +780,77 @@ func (n *NodeConfig) FromInterface(iface map[string]interface{}) error {
 		}
 	}
 
+	if logLevel, present := iface["log_level"]; present {
+		n.LogLevel = logLevel.(string)
+	}
+
+	if telemetry, present := iface["telemetry"]; present {
+		n.Telemetry = &Telemetry{}
+		if err := n.Telemetry.FromInterface(telemetry.(map[string]interface{})); err != nil {
+			return fmt.Errorf("failed to load telemetry config: %w", err)
+		}
+	}
+
+	if rawConfig, present := iface["raw_config"]; present {
+		n.RawConfig = rawConfig.(string)
+	}
+
+	if clusterProxy, present := iface["cluster_proxy"]; present {
+		n.ClusterProxy = clusterProxy.(bool)
+	}
+
+	return nil
+}
+
+// Clone returns a deep copy of the configuration by round-tripping it
+// through its JSON representation.
+func (n *NodeConfig) Clone() (*NodeConfig, error) {
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var iface map[string]interface{}
+	if err := json.Unmarshal(data, &iface); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	var clone NodeConfig
+	if err := clone.FromInterface(iface); err != nil {
+		return nil, fmt.Errorf("failed to translate config: %w", err)
+	}
+
+	return &clone, nil
+}
+
+// ApplyOpts adds each of the given options (listeners, storage, seals, and
+// so on) to the configuration.
+func (n *NodeConfig) ApplyOpts(opts ...NodeConfigOpt) error {
+	for index, opt := range opts {
+		switch tOpt := opt.(type) {
+		case Listener:
+			n.Listeners = append(n.Listeners, tOpt)
+		case Seal:
+			n.Seals = append(n.Seals, tOpt)
+		case Storage:
+			n.Storage = tOpt
+		case *DevConfig:
+			n.Dev = tOpt
+		case *UI:
+			n.UI = tOpt
+		case Audit:
+			n.Audits = append(n.Audits, tOpt)
+		case *Telemetry:
+			n.Telemetry = tOpt
+		default:
+			return fmt.Errorf("unknown type of node configuration option at index %d: %v (%T)", index, opt, opt)
+		}
+	}
+
 	return nil
 }
 
@@ -622,6 +907,12 @@ func (n *NodeConfig) Validate() error {
 		}
 	}
 
+	switch n.LogLevel {
+	case "", "trace", "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("unknown log level: `%v`; expected one of `trace`, `debug`, `info`, `warn`, or `error`", n.LogLevel)
+	}
+
 	return nil
 }
 
@@ -677,21 +968,11 @@ func (n *NodeConfig) ToConfig(directory string) (string, error) {
 		config += sConfig + "\n"
 
 		if _, ok := n.Storage.(*RaftStorage); ok {
-			// Need need to compute the cluster address. This is usually
-			// one port higher than the api address.
-			host, port, err := net.SplitHostPort(apiAddr)
+			_, clusterAddr, err := n.clusterAddrs(apiAddr)
 			if err != nil {
-				return "", fmt.Errorf("failed to parse API listen address (%v): %w", apiAddr, err)
+				return "", err
 			}
 
-			clusterPort, err := strconv.Atoi(port)
-			if err != nil {
-				return "", fmt.Errorf("failed to parse API listen address port (%v): %w", port, err)
-			}
-
-			clusterPort += 1
-			clusterAddr := fmt.Sprintf("%s:%d", host, clusterPort)
-
 			config += `cluster_addr = "` + scheme + "://" + clusterAddr + `"` + "\n"
 		}
 	}
@@ -714,6 +995,19 @@ func (n *NodeConfig) ToConfig(directory string) (string, error) {
 		config += lConfig + "\n"
 	}
 
+	if n.Telemetry != nil {
+		tConfig, err := n.Telemetry.ToConfig(directory)
+		if err != nil {
+			return "", fmt.Errorf("failed to build telemetry to config: %w", err)
+		}
+
+		config += tConfig + "\n"
+	}
+
+	if n.RawConfig != "" {
+		config += strings.TrimSpace(n.RawConfig) + "\n\n"
+	}
+
 	if n.Dev == nil {
 		config += `api_addr = "` + scheme + "://" + apiAddr + `"` + "\n"
 
@@ -727,7 +1021,12 @@ func (n *NodeConfig) ToConfig(directory string) (string, error) {
 		// Enable sys/raw
 		config += "raw_storage_endpoint = true\n"
 		config += "introspection_endpoint = true\n"
-		config += `log_level = "trace"` + "\n"
+		logLevel := n.LogLevel
+		if logLevel == "" {
+			logLevel = "trace"
+		}
+
+		config += `log_level = "` + logLevel + `"` + "\n"
 	}
 
 	return config, nil
@@ -766,6 +1065,39 @@ func (n *NodeConfig) GetConnectAddr(directory string) (string, bool, string, err
 	return "", false, "", fmt.Errorf("unknown connection address for configuration; last error: %w", lastErr)
 }
 
+// ClusterAddrs returns the address the node's cluster listener binds to
+// and the cluster_addr advertised to its peers.
+func (n *NodeConfig) ClusterAddrs(directory string) (string, string, error) {
+	apiAddr, _, _, err := n.GetConnectAddr(directory)
+	if err != nil {
+		return "", "", err
+	}
+
+	return n.clusterAddrs(apiAddr)
+}
+
+func (n *NodeConfig) clusterAddrs(apiAddr string) (string, string, error) {
+	// Need need to compute the cluster address. This is usually one port
+	// higher than the api address; the fault-injection proxy listens one
+	// port above that.
+	host, port, err := net.SplitHostPort(apiAddr)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse API listen address (%v): %w", apiAddr, err)
+	}
+
+	clusterPort, err := strconv.Atoi(port)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse API listen address port (%v): %w", port, err)
+	}
+
+	listenAddr := net.JoinHostPort(host, strconv.Itoa(clusterPort+1))
+	if !n.ClusterProxy {
+		return listenAddr, listenAddr, nil
+	}
+
+	return listenAddr, net.JoinHostPort(host, strconv.Itoa(clusterPort+2)), nil
+}
+
 func (n *NodeConfig) AddArgs(directory string) ([]string, error) {
 	args := []string{"server", "-exit-on-core-shutdown"}
 
@@ -788,8 +1120,8 @@ type DevConfig struct {
 }
 
 func (d *DevConfig) FromInterface(iface map[string]interface{}) error {
-	d.Token = iface["token"].(string)
-	d.Address = iface["address"].(string)
+	d.Token, _ = iface["token"].(string)
+	d.Address, _ = iface["address"].(string)
 
 	if _, present := iface["tls"]; present {
 		d.Tls = iface["tls"].(bool)
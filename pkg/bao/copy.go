@@ -0,0 +1,578 @@
+package bao
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/utils"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	CopyKindMount  = "mount"
+	CopyKindAuth   = "auth"
+	CopyKindPolicy = "policy"
+	CopyKindRole   = "role"
+	CopyKindKV     = "kv"
+)
+
+// CopyOptions selects what to copy between nodes: KV trees under the given
+// paths, ACL policies, auth methods with their userpass users and approle
+// roles, and secret engine mounts.
+type CopyOptions struct {
+	KVPaths  []string
+	Policies bool
+	Auth     bool
+	Mounts   bool
+
+	// UserpassPassword is set on userpass users which do not yet exist on
+	// the destination, as their passwords cannot be read from the source.
+	UserpassPassword string
+}
+
+// CopyItem is a single object to write to the destination, along with its
+// rendering before and after the write for showing a diff.
+type CopyItem struct {
+	Kind   string
+	Path   string
+	Before string
+	After  string
+
+	apply func(dst *api.Client) error
+}
+
+func (c *CopyItem) Diff() string {
+	return utils.LineDiff(c.Before, c.After)
+}
+
+func (c *CopyItem) Apply(dst *api.Client) error {
+	if err := c.apply(dst); err != nil {
+		return fmt.Errorf("failed to copy %v %v: %w", c.Kind, c.Path, err)
+	}
+
+	return nil
+}
+
+// copyPlan accumulates the items and warnings of a copy.
+type copyPlan struct {
+	src  *api.Client
+	dst  *api.Client
+	opts *CopyOptions
+
+	srcMounts map[string]*api.MountOutput
+	dstMounts map[string]*api.MountOutput
+	srcAuths  map[string]*api.AuthMount
+	dstAuths  map[string]*api.AuthMount
+
+	items    []*CopyItem
+	warnings []string
+}
+
+func renderStateJson(value interface{}) string {
+	if m, ok := value.(map[string]interface{}); value == nil || (ok && m == nil) {
+		return ""
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v\n", value)
+	}
+
+	return string(data) + "\n"
+}
+
+func (p *copyPlan) add(kind string, path string, before interface{}, after interface{}, apply func(dst *api.Client) error) {
+	item := &CopyItem{
+		Kind:  kind,
+		Path:  path,
+		After: renderStateJson(after),
+		apply: apply,
+	}
+
+	if text, ok := after.(string); ok {
+		item.After = text
+	}
+
+	if text, ok := before.(string); ok {
+		item.Before = text
+	} else {
+		item.Before = renderStateJson(before)
+	}
+
+	if item.Before == item.After {
+		return
+	}
+
+	p.items = append(p.items, item)
+}
+
+// PlanCopy reads the selected objects from src and compares them with dst,
+// returning the items which would change on dst, in the order they must be
+// applied, along with warnings about objects which cannot be copied.
+func PlanCopy(src *api.Client, dst *api.Client, opts *CopyOptions) ([]*CopyItem, []string, error) {
+	p := &copyPlan{src: src, dst: dst, opts: opts}
+
+	var err error
+	if p.srcMounts, err = src.Sys().ListMounts(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list source mounts: %w", err)
+	}
+
+	if p.dstMounts, err = dst.Sys().ListMounts(); err != nil {
+		return nil, nil, fmt.Errorf("failed to list destination mounts: %w", err)
+	}
+
+	if opts.Auth {
+		if p.srcAuths, err = src.Sys().ListAuth(); err != nil {
+			return nil, nil, fmt.Errorf("failed to list source auth methods: %w", err)
+		}
+
+		if p.dstAuths, err = dst.Sys().ListAuth(); err != nil {
+			return nil, nil, fmt.Errorf("failed to list destination auth methods: %w", err)
+		}
+	}
+
+	if opts.Mounts {
+		p.planMounts(CopyKindMount, p.srcMounts, p.dstMounts)
+	}
+
+	if opts.Auth {
+		p.planMounts(CopyKindAuth, p.srcAuths, p.dstAuths)
+	}
+
+	if opts.Policies {
+		if err := p.planPolicies(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if opts.Auth {
+		if err := p.planRoles(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, path := range opts.KVPaths {
+		if err := p.planKV(path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return p.items, p.warnings, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// mountSpec is the copyable part of a mount: its type, description,
+// options, and tunables.
+func mountSpec(mount *api.MountOutput) map[string]interface{} {
+	if mount == nil {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"type":                         mount.Type,
+		"description":                  mount.Description,
+		"options":                      mount.Options,
+		"default_lease_ttl":            mount.Config.DefaultLeaseTTL,
+		"max_lease_ttl":                mount.Config.MaxLeaseTTL,
+		"listing_visibility":           mount.Config.ListingVisibility,
+		"audit_non_hmac_request_keys":  mount.Config.AuditNonHMACRequestKeys,
+		"audit_non_hmac_response_keys": mount.Config.AuditNonHMACResponseKeys,
+		"passthrough_request_headers":  mount.Config.PassthroughRequestHeaders,
+		"allowed_response_headers":     mount.Config.AllowedResponseHeaders,
+		"token_type":                   mount.Config.TokenType,
+	}
+}
+
+// builtinMount reports whether the mount is one every node has and which
+// cannot be created or removed.
+func builtinMount(mount *api.MountOutput) bool {
+	switch mount.Type {
+	case "system", "cubbyhole", "identity", "token", "ns_system", "ns_cubbyhole", "ns_identity", "ns_token":
+		return true
+	}
+
+	return false
+}
+
+func mountConfigInput(mount *api.MountOutput) api.MountConfigInput {
+	description := mount.Description
+	return api.MountConfigInput{
+		Options:                   mount.Options,
+		Description:               &description,
+		DefaultLeaseTTL:           fmt.Sprintf("%ds", mount.Config.DefaultLeaseTTL),
+		MaxLeaseTTL:               fmt.Sprintf("%ds", mount.Config.MaxLeaseTTL),
+		ListingVisibility:         mount.Config.ListingVisibility,
+		AuditNonHMACRequestKeys:   mount.Config.AuditNonHMACRequestKeys,
+		AuditNonHMACResponseKeys:  mount.Config.AuditNonHMACResponseKeys,
+		PassthroughRequestHeaders: mount.Config.PassthroughRequestHeaders,
+		AllowedResponseHeaders:    mount.Config.AllowedResponseHeaders,
+		TokenType:                 mount.Config.TokenType,
+	}
+}
+
+// planMounts copies secret engine mounts or auth methods, creating those
+// missing on the destination and tuning the rest.
+func (p *copyPlan) planMounts(kind string, src map[string]*api.MountOutput, dst map[string]*api.MountOutput) {
+	for _, path := range sortedKeys(src) {
+		mount := src[path]
+		if builtinMount(mount) {
+			continue
+		}
+
+		tunePath := path
+		if kind == CopyKindAuth {
+			tunePath = "auth/" + path
+		}
+
+		existing := dst[path]
+		if existing != nil && existing.Type != mount.Type {
+			p.warnings = append(p.warnings, fmt.Sprintf("%v %v is of type %v on the source but %v on the destination; skipping", kind, path, mount.Type, existing.Type))
+			continue
+		}
+
+		p.add(kind, path, mountSpec(existing), mountSpec(mount), func(client *api.Client) error {
+			if existing != nil {
+				return client.Sys().TuneMount(tunePath, mountConfigInput(mount))
+			}
+
+			input := &api.MountInput{
+				Type:        mount.Type,
+				Description: mount.Description,
+				Config:      mountConfigInput(mount),
+				Local:       mount.Local,
+				SealWrap:    mount.SealWrap,
+				Options:     mount.Options,
+			}
+			input.Config.Description = nil
+
+			if kind == CopyKindAuth {
+				return client.Sys().EnableAuthWithOptions(path, input)
+			}
+
+			return client.Sys().Mount(path, input)
+		})
+	}
+}
+
+func (p *copyPlan) planPolicies() error {
+	names, err := p.src.Sys().ListPolicies()
+	if err != nil {
+		return fmt.Errorf("failed to list source policies: %w", err)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "root" {
+			continue
+		}
+
+		rules, err := p.src.Sys().GetPolicy(name)
+		if err != nil {
+			return fmt.Errorf("failed to read source policy %v: %w", name, err)
+		}
+
+		existing, err := p.dst.Sys().GetPolicy(name)
+		if err != nil {
+			return fmt.Errorf("failed to read destination policy %v: %w", name, err)
+		}
+
+		p.add(CopyKindPolicy, name, ensureNewline(existing), ensureNewline(rules), func(client *api.Client) error {
+			return client.Sys().PutPolicy(name, rules)
+		})
+	}
+
+	return nil
+}
+
+func ensureNewline(text string) string {
+	if text == "" || strings.HasSuffix(text, "\n") {
+		return text
+	}
+
+	return text + "\n"
+}
+
+func listKeys(client *api.Client, path string) ([]string, error) {
+	resp, err := client.Logical().List(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp == nil || resp.Data == nil {
+		return nil, nil
+	}
+
+	raw, _ := resp.Data["keys"].([]interface{})
+	var keys []string
+	for _, key := range raw {
+		if str, ok := key.(string); ok {
+			keys = append(keys, str)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func readData(client *api.Client, path string) (map[string]interface{}, error) {
+	resp, err := client.Logical().Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp == nil {
+		return nil, nil
+	}
+
+	return resp.Data, nil
+}
+
+// roleFields drops deprecated fields which may not be written alongside
+// their `token_`-prefixed replacements, along with fields which can only
+// be set on creation.
+func roleFields(data map[string]interface{}, existing bool) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	fields := map[string]interface{}{}
+	for key, value := range data {
+		fields[key] = value
+	}
+
+	for _, key := range []string{"policies", "ttl", "max_ttl", "period", "bound_cidrs", "num_uses"} {
+		if _, present := fields["token_"+key]; present {
+			delete(fields, key)
+		}
+	}
+
+	if existing {
+		delete(fields, "local_secret_ids")
+	}
+
+	return fields
+}
+
+// planRoles copies userpass users and approle roles (with their role IDs)
+// of every copied auth method. Userpass passwords and approle secret IDs
+// cannot be read and are never copied.
+func (p *copyPlan) planRoles() error {
+	for _, path := range sortedKeys(p.srcAuths) {
+		mount := p.srcAuths[path]
+
+		var listPath string
+		switch mount.Type {
+		case "userpass":
+			listPath = "auth/" + path + "users"
+		case "approle":
+			listPath = "auth/" + path + "role"
+		default:
+			continue
+		}
+
+		names, err := listKeys(p.src, listPath)
+		if err != nil {
+			return fmt.Errorf("failed to list roles of auth method %v: %w", path, err)
+		}
+
+		for _, name := range names {
+			rolePath := listPath + "/" + name
+			data, err := readData(p.src, rolePath)
+			if err != nil {
+				return fmt.Errorf("failed to read source %v: %w", rolePath, err)
+			}
+
+			// The role may have been removed since it was listed.
+			if data == nil {
+				continue
+			}
+
+			var existing map[string]interface{}
+			if p.dstAuths[path] != nil {
+				existing, err = readData(p.dst, rolePath)
+				if err != nil {
+					return fmt.Errorf("failed to read destination %v: %w", rolePath, err)
+				}
+			}
+
+			fields := roleFields(data, existing != nil)
+			before := roleFields(existing, true)
+			after := roleFields(data, true)
+
+			var roleID string
+			if mount.Type == "approle" {
+				if idData, err := readData(p.src, rolePath+"/role-id"); err == nil && idData != nil {
+					roleID, _ = idData["role_id"].(string)
+					after["role_id"] = roleID
+				}
+
+				if existing != nil {
+					if idData, err := readData(p.dst, rolePath+"/role-id"); err == nil && idData != nil {
+						before["role_id"] = idData["role_id"]
+					}
+				}
+			}
+
+			if mount.Type == "userpass" && existing == nil {
+				if p.opts.UserpassPassword == "" {
+					p.warnings = append(p.warnings, fmt.Sprintf("userpass user %v does not exist on the destination and its password cannot be copied; pass --userpass-password to create it", rolePath))
+					continue
+				}
+
+				fields["password"] = p.opts.UserpassPassword
+			}
+
+			p.add(CopyKindRole, rolePath, before, after, func(client *api.Client) error {
+				if _, err := client.Logical().Write(rolePath, fields); err != nil {
+					return err
+				}
+
+				if roleID == "" {
+					return nil
+				}
+
+				_, err := client.Logical().Write(rolePath+"/role-id", map[string]interface{}{
+					"role_id": roleID,
+				})
+				return err
+			})
+		}
+	}
+
+	return nil
+}
+
+// kvMount finds the KV mount containing path, returning the mount's path
+// and whether it is KV version 2.
+func kvMount(mounts map[string]*api.MountOutput, path string) (string, bool, bool) {
+	best := ""
+	for mountPath, mount := range mounts {
+		if (mount.Type == "kv" || mount.Type == "generic") && strings.HasPrefix(path, mountPath) && len(mountPath) > len(best) {
+			best = mountPath
+		}
+	}
+
+	if best == "" {
+		return "", false, false
+	}
+
+	return best, mounts[best].Options["version"] == "2", true
+}
+
+func kvDataPath(mount string, v2 bool, rel string) string {
+	if v2 {
+		return mount + "data/" + rel
+	}
+
+	return mount + rel
+}
+
+func kvListPath(mount string, v2 bool, rel string) string {
+	if v2 {
+		return mount + "metadata/" + rel
+	}
+
+	return mount + rel
+}
+
+func readKV(client *api.Client, mount string, v2 bool, rel string) (map[string]interface{}, error) {
+	data, err := readData(client, kvDataPath(mount, v2, rel))
+	if err != nil || data == nil || !v2 {
+		return data, err
+	}
+
+	// Deleted or destroyed versions have no data.
+	secret, _ := data["data"].(map[string]interface{})
+	return secret, nil
+}
+
+// planKV recursively copies the KV tree (or single secret) at path,
+// converting between KV versions 1 and 2 as necessary.
+func (p *copyPlan) planKV(path string) error {
+	path = strings.TrimPrefix(path, "/")
+	if !strings.Contains(path, "/") {
+		path += "/"
+	}
+
+	mount, srcV2, ok := kvMount(p.srcMounts, path)
+	if !ok {
+		return fmt.Errorf("no KV mount on the source contains %v", path)
+	}
+
+	dstV2 := srcV2
+	if dstMount, ok := p.dstMounts[mount]; ok {
+		if dstMount.Type != "kv" && dstMount.Type != "generic" {
+			return fmt.Errorf("mount %v on the destination is of type %v, not kv", mount, dstMount.Type)
+		}
+
+		dstV2 = dstMount.Options["version"] == "2"
+	} else if !p.opts.Mounts {
+		return fmt.Errorf("mount %v does not exist on the destination; copy it with --mounts", mount)
+	}
+
+	var walk func(rel string) error
+	walk = func(rel string) error {
+		if rel != "" && !strings.HasSuffix(rel, "/") {
+			data, err := readKV(p.src, mount, srcV2, rel)
+			if err != nil {
+				return fmt.Errorf("failed to read source secret %v%v: %w", mount, rel, err)
+			}
+
+			if data != nil {
+				var existing map[string]interface{}
+				if _, ok := p.dstMounts[mount]; ok {
+					existing, err = readKV(p.dst, mount, dstV2, rel)
+					if err != nil {
+						return fmt.Errorf("failed to read destination secret %v%v: %w", mount, rel, err)
+					}
+				}
+
+				dataPath := kvDataPath(mount, dstV2, rel)
+				p.add(CopyKindKV, mount+rel, existing, data, func(client *api.Client) error {
+					body := data
+					if dstV2 {
+						body = map[string]interface{}{"data": data}
+					}
+
+					_, err := client.Logical().Write(dataPath, body)
+					return err
+				})
+			}
+
+			// A path may be both a secret and a directory.
+			rel += "/"
+		}
+
+		keys, err := listKeys(p.src, kvListPath(mount, srcV2, rel))
+		if err != nil {
+			return fmt.Errorf("failed to list source secrets under %v%v: %w", mount, rel, err)
+		}
+
+		for _, key := range keys {
+			if err := walk(rel + key); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	rel := strings.TrimPrefix(path, mount)
+	if strings.HasSuffix(rel, "/") && rel != "" {
+		rel = strings.TrimSuffix(rel, "/")
+	}
+
+	return walk(rel)
+}
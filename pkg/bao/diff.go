@@ -0,0 +1,243 @@
+package bao
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openbao/devbao/pkg/utils"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	DiffKindMount  = "mount"
+	DiffKindAuth   = "auth"
+	DiffKindPolicy = "policy"
+	DiffKindAudit  = "audit"
+	DiffKindKV     = "kv"
+
+	DiffOnlyA   = "only-a"
+	DiffOnlyB   = "only-b"
+	DiffChanged = "changed"
+
+	// nodeDirPlaceholder replaces each node's directory in compared state,
+	// so that, e.g., audit logs kept next to each node do not differ.
+	nodeDirPlaceholder = "$NODE_DIR"
+)
+
+// StateDifference is a single object whose live state differs between two
+// nodes, rendered as text on each side along with a line diff from a to b.
+type StateDifference struct {
+	Kind   string `json:"kind"`
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	A      string `json:"a,omitempty"`
+	B      string `json:"b,omitempty"`
+	Diff   string `json:"diff"`
+}
+
+// stateSide is everything compared on one node, keyed by kind and path.
+type stateSide map[string]map[string]string
+
+func (s stateSide) add(kind string, path string, value interface{}, directory string) {
+	text, ok := value.(string)
+	if !ok {
+		text = renderStateJson(value)
+	}
+
+	if s[kind] == nil {
+		s[kind] = map[string]string{}
+	}
+
+	s[kind][path] = strings.ReplaceAll(text, directory, nodeDirPlaceholder)
+}
+
+// DiffNodes compares the live state of two running nodes: mounts, auth
+// methods, and their tunables; ACL policies; audit devices; and the
+// recursive key listings (not values) of the given KV paths.
+func DiffNodes(a *Node, b *Node, kvPaths []string) ([]*StateDifference, error) {
+	sideA, err := a.readState(kvPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	sideB, err := b.readState(kvPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	var differences []*StateDifference
+	for _, kind := range []string{DiffKindMount, DiffKindAuth, DiffKindPolicy, DiffKindAudit, DiffKindKV} {
+		paths := map[string]bool{}
+		for path := range sideA[kind] {
+			paths[path] = true
+		}
+		for path := range sideB[kind] {
+			paths[path] = true
+		}
+
+		for _, path := range sortedKeys(paths) {
+			textA, inA := sideA[kind][path]
+			textB, inB := sideB[kind][path]
+			if inA && inB && textA == textB {
+				continue
+			}
+
+			change := DiffChanged
+			switch {
+			case !inB:
+				change = DiffOnlyA
+			case !inA:
+				change = DiffOnlyB
+			}
+
+			differences = append(differences, &StateDifference{
+				Kind:   kind,
+				Path:   path,
+				Change: change,
+				A:      textA,
+				B:      textB,
+				Diff:   utils.LineDiff(textA, textB),
+			})
+		}
+	}
+
+	return differences, nil
+}
+
+func (n *Node) readState(kvPaths []string) (stateSide, error) {
+	client, err := n.GetClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for node %v: %w", n.Name, err)
+	}
+
+	directory := n.GetDirectory()
+	side := stateSide{}
+
+	mounts, err := client.Sys().ListMounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mounts of node %v: %w", n.Name, err)
+	}
+
+	for path, mount := range mounts {
+		if !builtinMount(mount) {
+			side.add(DiffKindMount, path, mountSpec(mount), directory)
+		}
+	}
+
+	auths, err := client.Sys().ListAuth()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auth methods of node %v: %w", n.Name, err)
+	}
+
+	for path, auth := range auths {
+		if !builtinMount(auth) {
+			side.add(DiffKindAuth, path, mountSpec(auth), directory)
+		}
+	}
+
+	policies, err := client.Sys().ListPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies of node %v: %w", n.Name, err)
+	}
+
+	for _, name := range policies {
+		if name == "root" {
+			continue
+		}
+
+		rules, err := client.Sys().GetPolicy(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy %v of node %v: %w", name, n.Name, err)
+		}
+
+		side.add(DiffKindPolicy, name, ensureNewline(rules), directory)
+	}
+
+	audits, err := client.Sys().ListAudit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit devices of node %v: %w", n.Name, err)
+	}
+
+	for path, audit := range audits {
+		side.add(DiffKindAudit, path, map[string]interface{}{
+			"type":        audit.Type,
+			"description": audit.Description,
+			"options":     audit.Options,
+			"local":       audit.Local,
+		}, directory)
+	}
+
+	for _, path := range kvPaths {
+		keys, present, err := listKVTree(client, mounts, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list KV keys of node %v: %w", n.Name, err)
+		}
+
+		// Leaving out paths without a KV mount reports them as only on
+		// the other node.
+		if !present {
+			continue
+		}
+
+		listing := ""
+		if len(keys) > 0 {
+			listing = strings.Join(keys, "\n") + "\n"
+		}
+
+		side.add(DiffKindKV, path, listing, directory)
+	}
+
+	return side, nil
+}
+
+// listKVTree recursively lists the secrets under the given KV path, as
+// full paths including the mount. It reports whether any KV mount contains
+// the path.
+func listKVTree(client *api.Client, mounts map[string]*api.MountOutput, path string) ([]string, bool, error) {
+	path = strings.TrimPrefix(path, "/")
+	if !strings.Contains(path, "/") {
+		path += "/"
+	}
+
+	mount, v2, ok := kvMount(mounts, path)
+	if !ok {
+		return nil, false, nil
+	}
+
+	var secrets []string
+	var walk func(rel string) error
+	walk = func(rel string) error {
+		keys, err := listKeys(client, kvListPath(mount, v2, rel))
+		if err != nil {
+			return fmt.Errorf("failed to list secrets under %v%v: %w", mount, rel, err)
+		}
+
+		for _, key := range keys {
+			if strings.HasSuffix(key, "/") {
+				if err := walk(rel + key); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			secrets = append(secrets, mount+rel+key)
+		}
+
+		return nil
+	}
+
+	rel := strings.TrimPrefix(path, mount)
+	if rel != "" && !strings.HasSuffix(rel, "/") {
+		rel += "/"
+	}
+
+	if err := walk(rel); err != nil {
+		return nil, true, err
+	}
+
+	sort.Strings(secrets)
+	return secrets, true, nil
+}
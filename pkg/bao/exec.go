@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/process"
@@ -57,6 +58,35 @@ func (e *ExecEnvironment) Kill() error {
 	return nil
 }
 
+func (e *ExecEnvironment) Reload() error {
+	if err := e.ValidateRunning(); err != nil {
+		return fmt.Errorf("unable to reload process: %w", err)
+	}
+
+	proc, err := process.NewProcess(int32(e.Pid))
+	if err != nil {
+		return fmt.Errorf("failed find process with pid (%d): %w", e.Pid, err)
+	}
+
+	if err := proc.SendSignal(syscall.SIGHUP); err != nil {
+		return fmt.Errorf("failed to send SIGHUP to process (%d): %w", e.Pid, err)
+	}
+
+	return nil
+}
+
+func (e *ExecEnvironment) WaitStopped() error {
+	for i := 0; i < 50; i++ {
+		if err := e.ValidateRunning(); err != nil {
+			return nil
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return fmt.Errorf("process with pid %d is still running after being stopped", e.Pid)
+}
+
 func (e *ExecEnvironment) ValidateRunning() error {
 	proc, err := process.NewProcess(int32(e.Pid))
 	if err != nil {
@@ -147,39 +177,6 @@ func (e *ExecEnvironment) ReadLogs() (string, error) {
 	return logs, nil
 }
 
-func Exec(env *ExecEnvironment) error {
-	binary, err := findBestBinary()
-	if err != nil {
-		return err
-	}
-
-	env.Binary = binary
-	return doExec(env)
-}
-
-func ExecBao(env *ExecEnvironment) error {
-	binary, err := expandBinary("openbao")
-	if err != nil {
-		binary, err = expandBinary("bao")
-	}
-	if err != nil {
-		return err
-	}
-
-	env.Binary = binary
-	return doExec(env)
-}
-
-func ExecVault(env *ExecEnvironment) error {
-	binary, err := expandBinary("vault")
-	if err != nil {
-		return err
-	}
-
-	env.Binary = binary
-	return doExec(env)
-}
-
 func findBestBinary() (string, error) {
 	binary, err := expandBinary("openbao")
 	if err == nil {
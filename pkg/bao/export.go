@@ -0,0 +1,635 @@
+package bao
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	ExportFormatSystemd = "systemd"
+	ExportFormatCompose = "compose"
+	ExportFormatK8s     = "k8s"
+)
+
+func ListExportFormats() []string {
+	return []string{
+		ExportFormatSystemd,
+		ExportFormatCompose,
+		ExportFormatK8s,
+	}
+}
+
+// exportedNode holds a node's configuration rendered for deployment outside
+// of devbao, with all paths rewritten to the target layout.
+type exportedNode struct {
+	Name    string
+	Group   string
+	Product string
+	Host    string
+	Scheme  string
+	APIPort int
+
+	ConfigDir string
+	DataDir   string
+	TLSDir    string
+	SealPath  string
+
+	Config string
+	Seal   string
+	TLS    *TLSConfig
+
+	StorageType string
+}
+
+func (e *exportedNode) Binary() string {
+	if e.Product == "vault" {
+		return "vault"
+	}
+
+	return "bao"
+}
+
+func (e *exportedNode) Image() string {
+	if e.Product == "vault" {
+		return "hashicorp/vault:latest"
+	}
+
+	return "openbao/openbao:latest"
+}
+
+func (e *exportedNode) APIAddr() string {
+	return fmt.Sprintf("%v://%v", e.Scheme, net.JoinHostPort(e.Host, strconv.Itoa(e.APIPort)))
+}
+
+func (e *exportedNode) ConfigArgs() []string {
+	args := []string{"-config=" + filepath.Join(e.ConfigDir, InstanceConfigName)}
+	if e.Seal != "" {
+		args = append(args, "-config="+e.SealPath)
+	}
+
+	return args
+}
+
+func (e *exportedNode) dataDirs() []string {
+	dirs := []string{filepath.Join(e.DataDir, "plugins")}
+	if e.StorageType == "raft" || e.StorageType == "file" {
+		dirs = append(dirs, filepath.Join(e.DataDir, "storage", e.StorageType))
+	}
+
+	return dirs
+}
+
+// startScript ensures storage and plugin directories exist before starting
+// the server, as fresh container volumes are empty.
+func (e *exportedNode) startScript() string {
+	script := fmt.Sprintf("mkdir -p %v && exec %v server", strings.Join(e.dataDirs(), " "), e.Binary())
+	for _, arg := range e.ConfigArgs() {
+		script += " " + arg
+	}
+
+	return script
+}
+
+const exportSealName = "seal.hcl"
+
+func (e *exportedNode) sealSecretName() string {
+	return e.Name + "-seal"
+}
+
+func exportProduct(node *Node) string {
+	if node.Type == "vault" {
+		return "vault"
+	}
+
+	return "openbao"
+}
+
+func newExportedNode(node *Node, format string, group string) (*exportedNode, error) {
+	if node.Config.Dev != nil {
+		return nil, fmt.Errorf("node %v is a dev mode instance and cannot be exported", node.Name)
+	}
+
+	addr, tls, _, err := node.Config.GetConnectAddr(node.GetDirectory())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection address for node %v: %w", node.Name, err)
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API address (%v): %w", addr, err)
+	}
+
+	apiPort, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API address port (%v): %w", port, err)
+	}
+
+	e := &exportedNode{
+		Name:    node.Name,
+		Group:   group,
+		Product: exportProduct(node),
+		Host:    host,
+		Scheme:  "http",
+		APIPort: apiPort,
+	}
+
+	if tls {
+		e.Scheme = "https"
+	}
+
+	switch format {
+	case ExportFormatSystemd:
+		e.ConfigDir = filepath.Join("/etc", e.Product, node.Name)
+		e.DataDir = filepath.Join("/var/lib", e.Product, node.Name)
+		e.TLSDir = filepath.Join(e.ConfigDir, "tls")
+		e.SealPath = filepath.Join(e.ConfigDir, exportSealName)
+	case ExportFormatCompose:
+		e.Host = node.Name
+		e.ConfigDir = filepath.Join("/", e.Product, "config")
+		e.DataDir = filepath.Join("/", e.Product, "file")
+		e.TLSDir = filepath.Join(e.ConfigDir, "tls")
+		e.SealPath = filepath.Join("/run/secrets", e.sealSecretName())
+	case ExportFormatK8s:
+		e.Host = fmt.Sprintf("%v-0.%v-internal", node.Name, group)
+		e.ConfigDir = filepath.Join("/", e.Product, "config")
+		e.DataDir = filepath.Join("/", e.Product, "file")
+		e.TLSDir = filepath.Join("/", e.Product, "secret")
+		e.SealPath = filepath.Join(e.TLSDir, exportSealName)
+	default:
+		return nil, fmt.Errorf("unknown export format: `%v`; supported formats are %v", format, strings.Join(ListExportFormats(), ", "))
+	}
+
+	return e, nil
+}
+
+// render builds the node's server configuration for the export target,
+// adding retry_join stanzas for the given peers' API addresses.
+func (e *exportedNode) render(node *Node, format string, peers []string) error {
+	cfg, err := node.Config.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to copy node configuration: %w", err)
+	}
+
+	// The fault-injection proxy only exists locally.
+	cfg.ClusterProxy = false
+
+	for _, listener := range cfg.Listeners {
+		tcp, ok := listener.(*TCPListener)
+		if !ok {
+			continue
+		}
+
+		if tcp.TLS != nil && e.TLS == nil {
+			e.TLS = tcp.TLS
+		}
+
+		if format != ExportFormatSystemd {
+			// Containers need to listen on all interfaces to be reachable.
+			_, port, err := net.SplitHostPort(tcp.Address)
+			if err != nil {
+				return fmt.Errorf("failed to parse listener address (%v): %w", tcp.Address, err)
+			}
+
+			tcp.Address = net.JoinHostPort("0.0.0.0", port)
+		}
+	}
+
+	switch storage := cfg.Storage.(type) {
+	case *RaftStorage:
+		storage.Path = ""
+		storage.RetryJoin = nil
+		for _, peer := range peers {
+			retryJoin := &RaftRetryJoin{LeaderAPIAddr: peer}
+			if e.TLS != nil {
+				retryJoin.LeaderCACertFile = filepath.Join(e.TLSDir, TLS_CA_NAME)
+			}
+
+			storage.RetryJoin = append(storage.RetryJoin, retryJoin)
+		}
+	case *FileStorage:
+		storage.Path = ""
+	}
+	e.StorageType = cfg.Storage.StorageType()
+
+	for index, seal := range cfg.Seals {
+		sConfig, err := seal.ToConfig(e.ConfigDir)
+		if err != nil {
+			return fmt.Errorf("failed to build seal %d to config: %w", index, err)
+		}
+
+		e.Seal += sConfig + "\n"
+	}
+	cfg.Seals = nil
+
+	staging, err := os.MkdirTemp("", "devbao-export-")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	config, err := cfg.ToConfig(staging)
+	if err != nil {
+		return fmt.Errorf("failed to build node's configuration (%s): %w", node.Name, err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(config, "\n") {
+		switch {
+		case strings.HasPrefix(line, "disable_mlock"):
+			continue
+		case strings.HasPrefix(line, "api_addr = "):
+			line, err = rewriteAddrLine(line, e.Host)
+		case strings.HasPrefix(line, "cluster_addr = "):
+			line, err = rewriteAddrLine(line, e.Host)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		lines = append(lines, line)
+	}
+
+	if format != ExportFormatSystemd {
+		// Containers lack the IPC_LOCK capability by default.
+		lines = append(lines, "disable_mlock = true")
+	}
+
+	config = strings.Join(lines, "\n")
+	config = strings.ReplaceAll(config, filepath.Join(staging, "storage"), filepath.Join(e.DataDir, "storage"))
+	config = strings.ReplaceAll(config, filepath.Join(staging, "plugins"), filepath.Join(e.DataDir, "plugins"))
+	config = strings.ReplaceAll(config, staging, e.TLSDir)
+
+	e.Config = strings.TrimSpace(config) + "\n"
+	return nil
+}
+
+func rewriteAddrLine(line string, host string) (string, error) {
+	parts := strings.SplitN(line, " = ", 2)
+	raw := strings.Trim(parts[1], `"`)
+
+	addr, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse address (%v): %w", raw, err)
+	}
+
+	addr.Host = net.JoinHostPort(host, addr.Port())
+	return parts[0] + ` = "` + addr.String() + `"`, nil
+}
+
+func writeExportFile(path string, contents string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory (%v): %w", filepath.Dir(path), err)
+	}
+
+	if err := os.WriteFile(path, []byte(contents), mode); err != nil {
+		return fmt.Errorf("failed to write %v: %w", path, err)
+	}
+
+	return nil
+}
+
+// writeConfigFiles writes the node's configuration, seal configuration, and
+// TLS material into the given directory.
+func (e *exportedNode) writeConfigFiles(directory string) ([]string, error) {
+	var written []string
+
+	path := filepath.Join(directory, InstanceConfigName)
+	if err := writeExportFile(path, e.Config, 0o644); err != nil {
+		return written, err
+	}
+	written = append(written, path)
+
+	if e.Seal != "" {
+		path := filepath.Join(directory, exportSealName)
+		if err := writeExportFile(path, e.Seal, 0o600); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+
+	if e.TLS != nil {
+		tlsDir := filepath.Join(directory, "tls")
+		if err := os.MkdirAll(tlsDir, 0o755); err != nil {
+			return written, fmt.Errorf("failed to create directory (%v): %w", tlsDir, err)
+		}
+
+		caPath := filepath.Join(tlsDir, TLS_CA_NAME)
+		certPath := filepath.Join(tlsDir, TLS_CERTS_NAME)
+		keyPath := filepath.Join(tlsDir, TLS_KEY_NAME)
+		if err := e.TLS.Write(caPath, certPath, keyPath); err != nil {
+			return written, fmt.Errorf("failed to write TLS material: %w", err)
+		}
+
+		if err := os.Chmod(keyPath, 0o600); err != nil {
+			return written, fmt.Errorf("failed to restrict permissions on %v: %w", keyPath, err)
+		}
+
+		written = append(written, caPath, certPath, keyPath)
+	}
+
+	return written, nil
+}
+
+func (e *exportedNode) systemdUnit() string {
+	unit := "[Unit]\n"
+	unit += fmt.Sprintf("Description=%v node %v (exported by devbao)\n", e.Product, e.Name)
+	unit += "Requires=network-online.target\n"
+	unit += "After=network-online.target\n"
+	unit += fmt.Sprintf("ConditionFileNotEmpty=%v\n", filepath.Join(e.ConfigDir, InstanceConfigName))
+	unit += "\n"
+	unit += "[Service]\n"
+	unit += fmt.Sprintf("User=%v\n", e.Product)
+	unit += fmt.Sprintf("Group=%v\n", e.Product)
+	unit += fmt.Sprintf("ExecStartPre=/usr/bin/mkdir -p %v\n", strings.Join(e.dataDirs(), " "))
+	unit += fmt.Sprintf("ExecStart=/usr/bin/%v server %v\n", e.Binary(), strings.Join(e.ConfigArgs(), " "))
+	unit += "ExecReload=/bin/kill --signal HUP $MAINPID\n"
+	unit += "KillMode=process\n"
+	unit += "KillSignal=SIGINT\n"
+	unit += "Restart=on-failure\n"
+	unit += "RestartSec=5\n"
+	unit += "LimitNOFILE=65536\n"
+	unit += "LimitMEMLOCK=infinity\n"
+	unit += "AmbientCapabilities=CAP_IPC_LOCK\n"
+	unit += "CapabilityBoundingSet=CAP_SYSLOG CAP_IPC_LOCK\n"
+	unit += fmt.Sprintf("StateDirectory=%v\n", filepath.Join(e.Product, e.Name))
+	unit += "ProtectSystem=full\n"
+	unit += "PrivateTmp=yes\n"
+	unit += "NoNewPrivileges=yes\n"
+	unit += "\n"
+	unit += "[Install]\n"
+	unit += "WantedBy=multi-user.target\n"
+	return unit
+}
+
+func (e *exportedNode) composeService() string {
+	svc := fmt.Sprintf("  %v:\n", e.Name)
+	svc += fmt.Sprintf("    image: %q\n", e.Image())
+	svc += fmt.Sprintf("    hostname: %q\n", e.Name)
+	svc += `    entrypoint: ["/bin/sh", "-c"]` + "\n"
+	svc += fmt.Sprintf("    command: [%q]\n", e.startScript())
+	svc += "    ports:\n"
+	svc += fmt.Sprintf("      - \"%d:%d\"\n", e.APIPort, e.APIPort)
+	svc += "    volumes:\n"
+	svc += fmt.Sprintf("      - %q\n", fmt.Sprintf("./%v/%v:%v:ro", e.Name, InstanceConfigName, filepath.Join(e.ConfigDir, InstanceConfigName)))
+	if e.TLS != nil {
+		svc += fmt.Sprintf("      - %q\n", fmt.Sprintf("./%v/tls:%v:ro", e.Name, e.TLSDir))
+	}
+	svc += fmt.Sprintf("      - %q\n", fmt.Sprintf("%v-data:%v", e.Name, e.DataDir))
+	if e.Seal != "" {
+		svc += "    secrets:\n"
+		svc += fmt.Sprintf("      - %q\n", e.sealSecretName())
+	}
+	return svc
+}
+
+func indentBlock(text string, spaces int) string {
+	prefix := strings.Repeat(" ", spaces)
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if line == "" {
+			lines = append(lines, "")
+			continue
+		}
+
+		lines = append(lines, prefix+line)
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (e *exportedNode) k8sManifests() string {
+	labels := "    app.kubernetes.io/name: " + e.Product + "\n"
+	labels += "    app.kubernetes.io/instance: " + e.Name + "\n"
+	labels += "    app.kubernetes.io/part-of: " + e.Group + "\n"
+	labels += "    app.kubernetes.io/managed-by: devbao\n"
+
+	m := "apiVersion: v1\n"
+	m += "kind: ConfigMap\n"
+	m += "metadata:\n"
+	m += "  name: " + e.Name + "-config\n"
+	m += "  labels:\n" + labels
+	m += "data:\n"
+	m += "  " + InstanceConfigName + ": |\n"
+	m += indentBlock(e.Config, 4)
+
+	m += "---\n"
+	m += "apiVersion: v1\n"
+	m += "kind: Secret\n"
+	m += "metadata:\n"
+	m += "  name: " + e.Name + "-secret\n"
+	m += "  labels:\n" + labels
+	m += "type: Opaque\n"
+	m += "stringData:\n"
+	if e.Seal == "" && e.TLS == nil {
+		m = strings.TrimSuffix(m, "stringData:\n") + "stringData: {}\n"
+	}
+	if e.Seal != "" {
+		m += "  " + exportSealName + ": |\n"
+		m += indentBlock(e.Seal, 4)
+	}
+	if e.TLS != nil {
+		chain := strings.Join(e.TLS.Certificates, "\n")
+		m += "  " + TLS_CA_NAME + ": |\n"
+		m += indentBlock(e.TLS.Certificates[len(e.TLS.Certificates)-1], 4)
+		m += "  " + TLS_CERTS_NAME + ": |\n"
+		m += indentBlock(chain, 4)
+		m += "  " + TLS_KEY_NAME + ": |\n"
+		m += indentBlock(e.TLS.Key, 4)
+	}
+
+	m += "---\n"
+	m += "apiVersion: v1\n"
+	m += "kind: Service\n"
+	m += "metadata:\n"
+	m += "  name: " + e.Name + "\n"
+	m += "  labels:\n" + labels
+	m += "spec:\n"
+	m += "  selector:\n"
+	m += "    app.kubernetes.io/instance: " + e.Name + "\n"
+	m += "  ports:\n"
+	m += fmt.Sprintf("    - name: api\n      port: %d\n      targetPort: %d\n", e.APIPort, e.APIPort)
+	m += fmt.Sprintf("    - name: cluster\n      port: %d\n      targetPort: %d\n", e.APIPort+1, e.APIPort+1)
+
+	m += "---\n"
+	m += "apiVersion: apps/v1\n"
+	m += "kind: StatefulSet\n"
+	m += "metadata:\n"
+	m += "  name: " + e.Name + "\n"
+	m += "  labels:\n" + labels
+	m += "spec:\n"
+	m += "  serviceName: " + e.Group + "-internal\n"
+	m += "  replicas: 1\n"
+	m += "  selector:\n"
+	m += "    matchLabels:\n"
+	m += "      app.kubernetes.io/instance: " + e.Name + "\n"
+	m += "  template:\n"
+	m += "    metadata:\n"
+	m += "      labels:\n" + indentBlock(labels, 4)
+	m += "    spec:\n"
+	m += "      containers:\n"
+	m += "        - name: " + e.Product + "\n"
+	m += fmt.Sprintf("          image: %q\n", e.Image())
+	m += fmt.Sprintf("          command: [\"/bin/sh\", \"-c\", %q]\n", e.startScript())
+	m += "          ports:\n"
+	m += fmt.Sprintf("            - name: api\n              containerPort: %d\n", e.APIPort)
+	m += fmt.Sprintf("            - name: cluster\n              containerPort: %d\n", e.APIPort+1)
+	m += "          volumeMounts:\n"
+	m += "            - name: config\n              mountPath: " + e.ConfigDir + "\n              readOnly: true\n"
+	m += "            - name: secret\n              mountPath: " + e.TLSDir + "\n              readOnly: true\n"
+	m += "            - name: data\n              mountPath: " + e.DataDir + "\n"
+	m += "      volumes:\n"
+	m += "        - name: config\n          configMap:\n            name: " + e.Name + "-config\n"
+	m += "        - name: secret\n          secret:\n            secretName: " + e.Name + "-secret\n"
+	m += "  volumeClaimTemplates:\n"
+	m += "    - metadata:\n"
+	m += "        name: data\n"
+	m += "      spec:\n"
+	m += "        accessModes: [\"ReadWriteOnce\"]\n"
+	m += "        resources:\n"
+	m += "          requests:\n"
+	m += "            storage: 1Gi\n"
+
+	return m
+}
+
+func k8sHeadlessService(group string, product string) string {
+	m := "apiVersion: v1\n"
+	m += "kind: Service\n"
+	m += "metadata:\n"
+	m += "  name: " + group + "-internal\n"
+	m += "  labels:\n"
+	m += "    app.kubernetes.io/part-of: " + group + "\n"
+	m += "    app.kubernetes.io/managed-by: devbao\n"
+	m += "spec:\n"
+	m += "  clusterIP: None\n"
+	m += "  publishNotReadyAddresses: true\n"
+	m += "  selector:\n"
+	m += "    app.kubernetes.io/part-of: " + group + "\n"
+	m += "    app.kubernetes.io/name: " + product + "\n"
+	return m
+}
+
+func writeExport(nodes []*exportedNode, format string, group string, outDir string) ([]string, error) {
+	var written []string
+
+	switch format {
+	case ExportFormatSystemd:
+		for _, e := range nodes {
+			directory := filepath.Join(outDir, e.Name)
+			files, err := e.writeConfigFiles(directory)
+			written = append(written, files...)
+			if err != nil {
+				return written, err
+			}
+
+			path := filepath.Join(directory, e.Name+".service")
+			if err := writeExportFile(path, e.systemdUnit(), 0o644); err != nil {
+				return written, err
+			}
+			written = append(written, path)
+		}
+	case ExportFormatCompose:
+		compose := "services:\n"
+		volumes := "volumes:\n"
+		secrets := ""
+		for _, e := range nodes {
+			files, err := e.writeConfigFiles(filepath.Join(outDir, e.Name))
+			written = append(written, files...)
+			if err != nil {
+				return written, err
+			}
+
+			compose += e.composeService()
+			volumes += fmt.Sprintf("  %v-data: {}\n", e.Name)
+			if e.Seal != "" {
+				secrets += fmt.Sprintf("  %v:\n    file: %q\n", e.sealSecretName(), "./"+e.Name+"/"+exportSealName)
+			}
+		}
+
+		if secrets != "" {
+			secrets = "secrets:\n" + secrets
+		}
+
+		path := filepath.Join(outDir, "docker-compose.yml")
+		if err := writeExportFile(path, compose+volumes+secrets, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	case ExportFormatK8s:
+		var manifests []string
+		manifests = append(manifests, k8sHeadlessService(group, nodes[0].Product))
+		for _, e := range nodes {
+			manifests = append(manifests, e.k8sManifests())
+		}
+
+		path := filepath.Join(outDir, group+".yaml")
+		if err := writeExportFile(path, strings.Join(manifests, "---\n"), 0o600); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+// ExportNode renders the node's configuration and deployment definitions for
+// the given format into outDir, returning the paths of written files.
+func ExportNode(node *Node, format string, outDir string) ([]string, error) {
+	e, err := newExportedNode(node, format, node.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.render(node, format, nil); err != nil {
+		return nil, err
+	}
+
+	return writeExport([]*exportedNode{e}, format, node.Name, outDir)
+}
+
+// ExportCluster renders every member of the cluster, with each node's raft
+// storage configured to retry_join all other members.
+func ExportCluster(cluster *Cluster, format string, outDir string) ([]string, error) {
+	var nodes []*Node
+	var exported []*exportedNode
+	for index, name := range cluster.Nodes {
+		node, err := LoadNode(name)
+		if err != nil {
+			return nil, fmt.Errorf("error loading node %d / %v: %w", index, name, err)
+		}
+
+		e, err := newExportedNode(node, format, cluster.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+		exported = append(exported, e)
+	}
+
+	if len(exported) == 0 {
+		return nil, fmt.Errorf("cluster %v has no nodes to export", cluster.Name)
+	}
+
+	for index, e := range exported {
+		var peers []string
+		for peerIndex, peer := range exported {
+			if peerIndex != index {
+				peers = append(peers, peer.APIAddr())
+			}
+		}
+
+		if err := e.render(nodes[index], format, peers); err != nil {
+			return nil, err
+		}
+	}
+
+	return writeExport(exported, format, cluster.Name, outDir)
+}
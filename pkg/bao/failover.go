@@ -0,0 +1,62 @@
+package bao
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	FailoverStepDown   = "step-down"
+	FailoverKillLeader = "kill-leader"
+	FailoverSealLeader = "seal-leader"
+)
+
+var FailoverModes = []string{FailoverStepDown, FailoverKillLeader, FailoverSealLeader}
+
+// WaitForNewLeader polls the cluster through GetLeader until a member other
+// than previous reports itself as the leader, returning it along with the
+// time elapsed since start.
+func (c *Cluster) WaitForNewLeader(previous string, start time.Time, timeout time.Duration, interval time.Duration) (*Node, *api.Client, time.Duration, error) {
+	for {
+		leaderNode, leaderClient, err := c.GetLeader()
+		elapsed := time.Since(start)
+		if err == nil && leaderNode.Name != previous {
+			return leaderNode, leaderClient, elapsed, nil
+		}
+
+		if elapsed > timeout {
+			if err == nil {
+				err = fmt.Errorf("%v remained leader", previous)
+			}
+
+			return nil, nil, elapsed, fmt.Errorf("no new leader elected after %v: %w", elapsed, err)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// WaitForMemberHealthy polls the named member until it is running,
+// unsealed, and following the leader as a standby.
+func (c *Cluster) WaitForMemberHealthy(name string, start time.Time, timeout time.Duration, interval time.Duration) (time.Duration, error) {
+	for {
+		node, err := LoadNode(name)
+		if err != nil {
+			return 0, fmt.Errorf("error loading node %v: %w", name, err)
+		}
+
+		status := memberStatus(node)
+		elapsed := time.Since(start)
+		if status.Running && !status.Sealed && (status.HAState == HAStateStandby || status.HAState == HAStatePerfStandby) {
+			return elapsed, nil
+		}
+
+		if elapsed > timeout {
+			return elapsed, fmt.Errorf("node %v did not rejoin as a standby after %v; last state: %v", name, elapsed, status.HAState)
+		}
+
+		time.Sleep(interval)
+	}
+}
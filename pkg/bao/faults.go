@@ -0,0 +1,165 @@
+package bao
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	FaultsJsonName = "faults.json"
+)
+
+// FaultRule describes a fault injected by the cluster's proxy on
+// connections from any of the From nodes to any of the To nodes; an empty
+// set matches every node.
+type FaultRule struct {
+	From []string `json:"from,omitempty"`
+	To   []string `json:"to,omitempty"`
+
+	Partition   bool          `json:"partition,omitempty"`
+	Latency     time.Duration `json:"latency,omitempty"`
+	Jitter      time.Duration `json:"jitter,omitempty"`
+	DropPercent int           `json:"drop_percent,omitempty"`
+}
+
+type Faults struct {
+	Rules []*FaultRule `json:"rules"`
+}
+
+func (r *FaultRule) Matches(from string, to string) bool {
+	fromOk := len(r.From) == 0 || slices.Contains(r.From, from)
+	toOk := len(r.To) == 0 || slices.Contains(r.To, to)
+	return fromOk && toOk
+}
+
+func (r *FaultRule) String() string {
+	describe := func(nodes []string) string {
+		if len(nodes) == 0 {
+			return "*"
+		}
+
+		return strings.Join(nodes, ",")
+	}
+
+	var effects []string
+	if r.Partition {
+		effects = append(effects, "partition")
+	}
+
+	if r.Latency > 0 || r.Jitter > 0 {
+		effects = append(effects, fmt.Sprintf("delay %v±%v", r.Latency, r.Jitter))
+	}
+
+	if r.DropPercent > 0 {
+		effects = append(effects, fmt.Sprintf("drop %d%%", r.DropPercent))
+	}
+
+	return fmt.Sprintf("%v -> %v: %v", describe(r.From), describe(r.To), strings.Join(effects, ", "))
+}
+
+// Effect combines all rules matching the link from one node to another
+// into a single rule, taking the most severe value of each fault.
+func (f *Faults) Effect(from string, to string) *FaultRule {
+	effect := &FaultRule{From: []string{from}, To: []string{to}}
+	for _, rule := range f.Rules {
+		if !rule.Matches(from, to) {
+			continue
+		}
+
+		effect.Partition = effect.Partition || rule.Partition
+		effect.Latency = max(effect.Latency, rule.Latency)
+		effect.Jitter = max(effect.Jitter, rule.Jitter)
+		effect.DropPercent = max(effect.DropPercent, rule.DropPercent)
+	}
+
+	return effect
+}
+
+// Partitioned reports whether the link between two nodes is cut in either
+// direction; a TCP connection cannot carry traffic one way only.
+func (f *Faults) Partitioned(a string, b string) bool {
+	return f.Effect(a, b).Partition || f.Effect(b, a).Partition
+}
+
+func (c *Cluster) faultsPath() string {
+	return filepath.Join(c.GetDirectory(), FaultsJsonName)
+}
+
+// LoadFaults reads the cluster's fault rules; a cluster without any has an
+// empty rule set.
+func (c *Cluster) LoadFaults() (*Faults, error) {
+	path := c.faultsPath()
+	faultsFile, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Faults{}, nil
+		}
+
+		return nil, fmt.Errorf("failed to open faults file (`%v`) for reading: %w", path, err)
+	}
+
+	defer faultsFile.Close()
+
+	var faults Faults
+	if err := json.NewDecoder(faultsFile).Decode(&faults); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal faults: %w", err)
+	}
+
+	return &faults, nil
+}
+
+func (c *Cluster) SaveFaults(faults *Faults) error {
+	for _, rule := range faults.Rules {
+		for _, name := range append(slices.Clone(rule.From), rule.To...) {
+			if !slices.Contains(c.Nodes, name) {
+				return fmt.Errorf("node %v is not a member of cluster %v", name, c.Name)
+			}
+		}
+
+		if rule.DropPercent < 0 || rule.DropPercent > 100 {
+			return fmt.Errorf("drop percentage must be between 0 and 100; got %v", rule.DropPercent)
+		}
+
+		if rule.Latency < 0 || rule.Jitter < 0 {
+			return fmt.Errorf("latency and jitter must not be negative")
+		}
+	}
+
+	// Write to a temporary file first so that the proxy never reads a
+	// partially written rule set.
+	path := c.faultsPath()
+	tmpPath := path + ".tmp"
+	faultsFile, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open faults file (`%v`) for writing: %w", tmpPath, err)
+	}
+
+	defer faultsFile.Close()
+
+	if err := json.NewEncoder(faultsFile).Encode(faults); err != nil {
+		return fmt.Errorf("failed to marshal faults: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace faults file (`%v`): %w", path, err)
+	}
+
+	return nil
+}
+
+// AddFaults appends rules to the cluster's existing fault rules.
+func (c *Cluster) AddFaults(rules ...*FaultRule) (*Faults, error) {
+	faults, err := c.LoadFaults()
+	if err != nil {
+		return nil, err
+	}
+
+	faults.Rules = append(faults.Rules, rules...)
+	return faults, c.SaveFaults(faults)
+}
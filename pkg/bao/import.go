@@ -0,0 +1,501 @@
+package bao
+
+import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/printer"
+
+	"github.com/openbao/devbao/pkg/utils"
+)
+
+// Top-level attributes which devbao always regenerates for the node and so
+// are dropped when importing an existing configuration.
+var generatedConfigKeys = map[string]bool{
+	"api_addr":               true,
+	"cluster_addr":           true,
+	"disable_mlock":          true,
+	"plugin_directory":       true,
+	"raw_storage_endpoint":   true,
+	"introspection_endpoint": true,
+	"pid_file":               true,
+}
+
+// ParseServerConfig translates an existing server HCL configuration into
+// a NodeConfig. Listener, storage, seal, ui, telemetry, and log level
+// stanzas are modeled; other stanzas are preserved verbatim in RawConfig.
+// Relative paths (such as TLS certificates) are resolved against configDir.
+func ParseServerConfig(contents string, configDir string) (*NodeConfig, []string, error) {
+	file, err := hcl.ParseString(contents)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected root of configuration: %T", file.Node)
+	}
+
+	cfg := &NodeConfig{}
+	var warnings []string
+	var raw []string
+
+	for _, item := range list.Items {
+		key := item.Keys[0].Token.Value().(string)
+		var labels []string
+		for _, label := range item.Keys[1:] {
+			labels = append(labels, label.Token.Value().(string))
+		}
+
+		keepRaw := false
+		switch key {
+		case "listener":
+			listener, lWarnings, err := parseListenerStanza(labels, item.Val, configDir)
+			if err != nil {
+				return nil, warnings, fmt.Errorf("failed to parse listener: %w", err)
+			}
+
+			warnings = append(warnings, lWarnings...)
+			cfg.Listeners = append(cfg.Listeners, listener)
+		case "storage", "backend":
+			if cfg.Storage != nil {
+				return nil, warnings, fmt.Errorf("multiple storage stanzas present")
+			}
+
+			storage, sWarnings, err := parseStorageStanza(labels, item.Val, configDir)
+			if err != nil {
+				return nil, warnings, fmt.Errorf("failed to parse storage: %w", err)
+			}
+
+			warnings = append(warnings, sWarnings...)
+			cfg.Storage = storage
+		case "seal":
+			if len(labels) == 1 && labels[0] == "transit" {
+				seal, sWarnings, err := parseTransitSealStanza(item.Val)
+				if err != nil {
+					return nil, warnings, fmt.Errorf("failed to parse transit seal: %w", err)
+				}
+
+				warnings = append(warnings, sWarnings...)
+				cfg.Seals = append(cfg.Seals, seal)
+			} else {
+				keepRaw = true
+			}
+		case "telemetry":
+			telemetry, tWarnings, err := parseTelemetryStanza(item.Val)
+			if err != nil {
+				return nil, warnings, fmt.Errorf("failed to parse telemetry: %w", err)
+			}
+
+			warnings = append(warnings, tWarnings...)
+			cfg.Telemetry = telemetry
+		case "ui":
+			var enabled interface{}
+			if err := hcl.DecodeObject(&enabled, item.Val); err != nil {
+				return nil, warnings, fmt.Errorf("failed to decode ui: %w", err)
+			}
+
+			value, err := toBool(enabled)
+			if err != nil {
+				return nil, warnings, fmt.Errorf("failed to parse ui: %w", err)
+			}
+
+			cfg.UI = &UI{Enabled: value}
+		case "log_level":
+			var level string
+			if err := hcl.DecodeObject(&level, item.Val); err != nil {
+				return nil, warnings, fmt.Errorf("failed to decode log_level: %w", err)
+			}
+
+			cfg.LogLevel = strings.ToLower(level)
+		default:
+			if generatedConfigKeys[key] {
+				warnings = append(warnings, fmt.Sprintf("dropping `%v`; it is generated by devbao", key))
+			} else {
+				keepRaw = true
+			}
+		}
+
+		if keepRaw {
+			var buf bytes.Buffer
+			if err := printer.Fprint(&buf, item); err != nil {
+				return nil, warnings, fmt.Errorf("failed to preserve `%v` stanza: %w", key, err)
+			}
+
+			raw = append(raw, buf.String())
+			warnings = append(warnings, fmt.Sprintf("preserving unmodeled `%v` stanza verbatim", strings.Join(append([]string{key}, labels...), " ")))
+		}
+	}
+
+	cfg.RawConfig = strings.Join(raw, "\n\n")
+
+	return cfg, warnings, nil
+}
+
+func decodeStanza(name string, labels []string, node ast.Node) (map[string]interface{}, error) {
+	if len(labels) != 1 {
+		return nil, fmt.Errorf("expected exactly one label on %v stanza; got %v", name, labels)
+	}
+
+	var data map[string]interface{}
+	if err := hcl.DecodeObject(&data, node); err != nil {
+		return nil, fmt.Errorf("failed to decode %v stanza: %w", name, err)
+	}
+
+	return data, nil
+}
+
+func toBool(value interface{}) (bool, error) {
+	switch tValue := value.(type) {
+	case bool:
+		return tValue, nil
+	case string:
+		return strconv.ParseBool(tValue)
+	case int:
+		return tValue != 0, nil
+	}
+
+	return false, fmt.Errorf("unable to interpret %v (%T) as a boolean", value, value)
+}
+
+func toInt(value interface{}) (int, error) {
+	switch tValue := value.(type) {
+	case int:
+		return tValue, nil
+	case int64:
+		return int(tValue), nil
+	case float64:
+		return int(tValue), nil
+	case string:
+		return strconv.Atoi(tValue)
+	}
+
+	return 0, fmt.Errorf("unable to interpret %v (%T) as an integer", value, value)
+}
+
+func unusedKeys(stanza string, data map[string]interface{}, used ...string) []string {
+	var warnings []string
+	var keys []string
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+outer:
+	for _, key := range keys {
+		for _, usedKey := range used {
+			if key == usedKey {
+				continue outer
+			}
+		}
+
+		warnings = append(warnings, fmt.Sprintf("dropping unsupported `%v` attribute on %v stanza", key, stanza))
+	}
+
+	return warnings
+}
+
+func resolvePath(path string, configDir string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(configDir, path)
+}
+
+func parseListenerStanza(labels []string, node ast.Node, configDir string) (Listener, []string, error) {
+	data, err := decodeStanza("listener", labels, node)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch labels[0] {
+	case "tcp":
+		listener := &TCPListener{Address: "127.0.0.1:8200"}
+		if address, ok := data["address"].(string); ok {
+			listener.Address = address
+		}
+
+		tlsDisable := false
+		if value, present := data["tls_disable"]; present {
+			tlsDisable, err = toBool(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to parse tls_disable: %w", err)
+			}
+		}
+
+		certPath, _ := data["tls_cert_file"].(string)
+		keyPath, _ := data["tls_key_file"].(string)
+		if !tlsDisable {
+			if certPath == "" || keyPath == "" {
+				return nil, nil, fmt.Errorf("tcp listener (%v) has TLS enabled but is missing tls_cert_file or tls_key_file", listener.Address)
+			}
+
+			tls, err := readTLSConfig(resolvePath(certPath, configDir), resolvePath(keyPath, configDir))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read TLS material for tcp listener (%v): %w", listener.Address, err)
+			}
+
+			listener.TLS = tls
+			listener.TLSFiles = true
+		}
+
+		warnings := unusedKeys("listener \"tcp\"", data, "address", "tls_disable", "tls_cert_file", "tls_key_file")
+		return listener, warnings, nil
+	case "unix":
+		listener := &UnixListener{}
+		if address, ok := data["address"].(string); ok {
+			listener.Path = address
+		}
+
+		warnings := unusedKeys("listener \"unix\"", data, "address")
+		return listener, warnings, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown listener type: `%v`", labels[0])
+}
+
+func readTLSConfig(certPath string, keyPath string) (*TLSConfig, error) {
+	certData, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificates (%v): %w", certPath, err)
+	}
+
+	keyData, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key (%v): %w", keyPath, err)
+	}
+
+	tls := &TLSConfig{
+		Key: string(keyData),
+	}
+
+	rest := certData
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		tls.Certificates = append(tls.Certificates, string(pem.EncodeToMemory(block)))
+	}
+
+	if len(tls.Certificates) == 0 {
+		return nil, fmt.Errorf("no PEM certificates found in %v", certPath)
+	}
+
+	return tls, nil
+}
+
+func parseStorageStanza(labels []string, node ast.Node, configDir string) (Storage, []string, error) {
+	data, err := decodeStanza("storage", labels, node)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	path, _ := data["path"].(string)
+	path = resolvePath(path, configDir)
+
+	switch labels[0] {
+	case "raft":
+		warnings := unusedKeys("storage \"raft\"", data, "path", "node_id", "performance_multiplier", "snapshot_threshold", "trailing_logs", "max_entry_size", "retry_join", "retry_join_as_non_voter")
+		storage := &RaftStorage{Path: path}
+		storage.NodeID, _ = data["node_id"].(string)
+
+		for key, field := range map[string]*int{
+			"performance_multiplier": &storage.PerformanceMultiplier,
+			"snapshot_threshold":     &storage.SnapshotThreshold,
+			"trailing_logs":          &storage.TrailingLogs,
+			"max_entry_size":         &storage.MaxEntrySize,
+		} {
+			if raw, present := data[key]; present {
+				value, err := toInt(raw)
+				if err != nil {
+					return nil, warnings, fmt.Errorf("failed to parse %v: %w", key, err)
+				}
+
+				*field = value
+			}
+		}
+
+		if nonVoter, present := data["retry_join_as_non_voter"]; present {
+			value, err := toBool(nonVoter)
+			if err != nil {
+				return nil, warnings, fmt.Errorf("failed to parse retry_join_as_non_voter: %w", err)
+			}
+
+			storage.RetryJoinAsNonVoter = value
+		}
+
+		entries, _ := data["retry_join"].([]map[string]interface{})
+		for _, entry := range entries {
+			retryJoin := &RaftRetryJoin{}
+			if err := retryJoin.FromInterface(entry); err != nil {
+				return nil, warnings, fmt.Errorf("failed to parse retry_join: %w", err)
+			}
+
+			warnings = append(warnings, unusedKeys("retry_join", entry, "leader_api_addr", "leader_tls_servername", "leader_ca_cert_file", "leader_client_cert_file", "leader_client_key_file")...)
+			retryJoin.LeaderCACertFile = resolvePath(retryJoin.LeaderCACertFile, configDir)
+			retryJoin.LeaderClientCertFile = resolvePath(retryJoin.LeaderClientCertFile, configDir)
+			retryJoin.LeaderClientKeyFile = resolvePath(retryJoin.LeaderClientKeyFile, configDir)
+			storage.RetryJoin = append(storage.RetryJoin, retryJoin)
+		}
+
+		return storage, warnings, nil
+	case "file":
+		warnings := unusedKeys("storage \"file\"", data, "path")
+		return &FileStorage{Path: path}, warnings, nil
+	case "inmem":
+		warnings := unusedKeys("storage \"inmem\"", data)
+		return &InmemStorage{}, warnings, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported storage type: `%v`; supported types are `raft`, `file`, and `inmem`", labels[0])
+}
+
+func parseTransitSealStanza(node ast.Node) (Seal, []string, error) {
+	data, err := decodeStanza("seal", []string{"transit"}, node)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seal := &TransitSeal{}
+	seal.Address, _ = data["address"].(string)
+	seal.Token, _ = data["token"].(string)
+	seal.MountPath, _ = data["mount_path"].(string)
+	seal.KeyName, _ = data["key_name"].(string)
+	if value, present := data["disabled"]; present {
+		seal.Disabled, err = toBool(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse disabled: %w", err)
+		}
+	}
+
+	warnings := unusedKeys("seal \"transit\"", data, "address", "token", "mount_path", "key_name", "disabled")
+	return seal, warnings, nil
+}
+
+func parseTelemetryStanza(node ast.Node) (*Telemetry, []string, error) {
+	var data map[string]interface{}
+	if err := hcl.DecodeObject(&data, node); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode telemetry stanza: %w", err)
+	}
+
+	telemetry := &Telemetry{}
+	telemetry.PrometheusRetentionTime, _ = data["prometheus_retention_time"].(string)
+	telemetry.StatsdAddress, _ = data["statsd_address"].(string)
+	telemetry.StatsiteAddress, _ = data["statsite_address"].(string)
+	telemetry.UsageGaugePeriod, _ = data["usage_gauge_period"].(string)
+	if value, present := data["disable_hostname"]; present {
+		disable, err := toBool(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse disable_hostname: %w", err)
+		}
+
+		telemetry.DisableHostname = disable
+	}
+
+	warnings := unusedKeys("telemetry", data, "prometheus_retention_time", "disable_hostname", "statsd_address", "statsite_address", "usage_gauge_period")
+	return telemetry, warnings, nil
+}
+
+// ImportNode registers a new node from an existing server configuration
+// file. When storageDir is given, its contents are either copied into the
+// node's directory or, when link is set, referenced in place.
+func ImportNode(name string, product string, configPath string, storageDir string, link bool) (*Node, []string, error) {
+	contents, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read configuration (%v): %w", configPath, err)
+	}
+
+	configDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve configuration directory: %w", err)
+	}
+
+	cfg, warnings, err := ParseServerConfig(string(contents), configDir)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	if cfg.Storage == nil {
+		return nil, warnings, fmt.Errorf("configuration (%v) has no storage stanza", configPath)
+	}
+
+	n := &Node{
+		Name:   name,
+		Type:   product,
+		Config: *cfg,
+	}
+
+	if storageDir != "" {
+		storageDir, err = filepath.Abs(storageDir)
+		if err != nil {
+			return nil, warnings, fmt.Errorf("failed to resolve storage directory: %w", err)
+		}
+
+		if _, err := os.Stat(storageDir); err != nil {
+			return nil, warnings, fmt.Errorf("failed to find storage directory: %w", err)
+		}
+	}
+
+	// The storage path in the original configuration refers to the other
+	// machine; only reference a directory when explicitly asked to.
+	path := ""
+	if storageDir != "" && link {
+		path = storageDir
+	}
+
+	switch storage := n.Config.Storage.(type) {
+	case *RaftStorage:
+		if storage.Path != "" && storageDir == "" {
+			warnings = append(warnings, fmt.Sprintf("existing raft storage path (%v) is not referenced by the imported node; use --storage to import its contents", storage.Path))
+		}
+		storage.Path = path
+	case *FileStorage:
+		if storage.Path != "" && storageDir == "" {
+			warnings = append(warnings, fmt.Sprintf("existing file storage path (%v) is not referenced by the imported node; use --storage to import its contents", storage.Path))
+		}
+		storage.Path = path
+	default:
+		if path != "" {
+			return nil, warnings, fmt.Errorf("unable to reference storage for %v storage backend", n.Config.Storage.StorageType())
+		}
+	}
+
+	if err := n.SaveConfig(); err != nil {
+		return nil, warnings, fmt.Errorf("failed saving initial configuration: %w", err)
+	}
+
+	if storageDir != "" && !link {
+		storageType := n.Config.Storage.StorageType()
+		if storageType == "inmem" {
+			return nil, warnings, fmt.Errorf("unable to copy storage for inmem storage backend")
+		}
+
+		target := filepath.Join(n.GetDirectory(), "storage", storageType)
+		if err := utils.CopyDir(storageDir, target); err != nil {
+			return nil, warnings, fmt.Errorf("failed to copy storage into node directory: %w", err)
+		}
+	}
+
+	config, err := n.RenderConfig()
+	if err != nil {
+		return nil, warnings, fmt.Errorf("failed to build node's configuration (%s): %w", n.Name, err)
+	}
+
+	if _, err := n.SaveInstanceConfig(config); err != nil {
+		return nil, warnings, fmt.Errorf("error persisting node configuration: %w", err)
+	}
+
+	return n, warnings, nil
+}
@@ -0,0 +1,141 @@
+package bao
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	MigrateConfigName = "migrate.hcl"
+)
+
+// storagePath returns where storage backed by the node's directory lives
+// on disk, or an empty string for in-memory storage.
+func storagePath(storage Storage, directory string) string {
+	switch storage.(type) {
+	case *RaftStorage:
+		return filepath.Join(directory, nodeStorageDir, "raft")
+	case *FileStorage:
+		return filepath.Join(directory, nodeStorageDir, "file")
+	}
+
+	return ""
+}
+
+// migrateStanza renders the storage's configuration as the given `operator
+// migrate` stanza, e.g., `storage_source`.
+func migrateStanza(storage Storage, stanza string, directory string) (string, error) {
+	config, err := storage.ToConfig(directory)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Replace(config, "storage ", stanza+" ", 1), nil
+}
+
+// MigrateStorage moves the node's data to a new storage backend (`raft` or
+// `file`) with `operator migrate`, run with the node's own binary while it
+// is stopped. On success, the node's configuration is swapped over to the
+// new backend, the old storage is removed, and the node is resumed and
+// unsealed if it was running.
+func (n *Node) MigrateStorage(to string) error {
+	if err := n.ValidateLocalStorage(); err != nil {
+		return fmt.Errorf("unable to migrate storage: %w", err)
+	}
+
+	if n.Cluster != "" {
+		return fmt.Errorf("node %v is a member of cluster %v; remove it from the cluster before migrating its storage", n.Name, n.Cluster)
+	}
+
+	from := n.Config.Storage.StorageType()
+	if from == to {
+		return fmt.Errorf("node %v already uses %v storage", n.Name, to)
+	}
+
+	var destination Storage
+	switch to {
+	case "raft":
+		destination = &RaftStorage{NodeID: n.Name}
+	case "file":
+		destination = &FileStorage{}
+	default:
+		return fmt.Errorf("unknown destination storage type: `%v`; valid values are `raft` and `file`", to)
+	}
+
+	binary, err := n.ServerBinary()
+	if err != nil {
+		return fmt.Errorf("failed to find binary for node %v: %w", n.Name, err)
+	}
+
+	directory := n.GetDirectory()
+	source := n.Config.Storage
+
+	return n.whileStopped(func() error {
+		// A destination left over from an earlier migration would otherwise
+		// resurrect entries deleted since.
+		destinationPath := storagePath(destination, directory)
+		if err := os.RemoveAll(destinationPath); err != nil {
+			return fmt.Errorf("failed to clear destination storage (%v): %w", destinationPath, err)
+		}
+
+		sourceConfig, err := migrateStanza(source, "storage_source", directory)
+		if err != nil {
+			return fmt.Errorf("failed to build source storage configuration: %w", err)
+		}
+
+		destinationConfig, err := migrateStanza(destination, "storage_destination", directory)
+		if err != nil {
+			return fmt.Errorf("failed to build destination storage configuration: %w", err)
+		}
+
+		config := sourceConfig + "\n" + destinationConfig
+		if to == "raft" {
+			_, advertise, err := n.Config.ClusterAddrs(directory)
+			if err != nil {
+				return err
+			}
+
+			config += fmt.Sprintf("\ncluster_addr = \"http://%v\"\n", advertise)
+		}
+
+		path := filepath.Join(directory, MigrateConfigName)
+		if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+			return fmt.Errorf("failed to write migration configuration (%v): %w", path, err)
+		}
+
+		defer os.Remove(path)
+
+		cmd := exec.Command(binary, "operator", "migrate", "-config="+path)
+		cmd.Dir = directory
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to migrate storage (cli: %v): %w", cmd.Args, err)
+		}
+
+		n.Config.Storage = destination
+		if err := n.SaveConfig(); err != nil {
+			return fmt.Errorf("failed to save node configuration: %w", err)
+		}
+
+		rendered, err := n.RenderConfig()
+		if err != nil {
+			return fmt.Errorf("failed to render configuration: %w", err)
+		}
+
+		if _, err := n.SaveInstanceConfig(rendered); err != nil {
+			return fmt.Errorf("failed to save instance configuration: %w", err)
+		}
+
+		if sourcePath := storagePath(source, directory); sourcePath != "" {
+			if err := os.RemoveAll(sourcePath); err != nil {
+				return fmt.Errorf("failed to remove previous %v storage (%v): %w", from, sourcePath, err)
+			}
+		}
+
+		return nil
+	})
+}
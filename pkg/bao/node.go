@@ -9,6 +9,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/openbao/openbao/api/v2"
 )
@@ -32,6 +33,20 @@ type Node struct {
 
 	Cluster  string `json:"cluster,omitempty"`
 	NonVoter bool   `json:"non_voter"`
+
+	// Adopted nodes were started outside of devbao; their original
+	// command line and configuration are reused and their configuration
+	// and storage directories are never removed.
+	Adopted bool `json:"adopted,omitempty"`
+
+	// Binary pins the server binary the node runs, either by the name of a
+	// registered binary or by path; otherwise, it is resolved from the
+	// node's type on every resume.
+	Binary string `json:"binary,omitempty"`
+
+	// Version is the server version of the node's binary, recorded when
+	// the node is started or pinned so that it need not be run again.
+	Version string `json:"version,omitempty"`
 }
 
 func (n *Node) FromInterface(iface map[string]interface{}) error {
@@ -54,6 +69,22 @@ func (n *Node) FromInterface(iface map[string]interface{}) error {
 		n.Cluster = iface["cluster"].(string)
 	}
 
+	if nonVoter, ok := iface["non_voter"].(bool); ok {
+		n.NonVoter = nonVoter
+	}
+
+	if adopted, ok := iface["adopted"].(bool); ok {
+		n.Adopted = adopted
+	}
+
+	if binary, ok := iface["binary"].(string); ok {
+		n.Binary = binary
+	}
+
+	if version, ok := iface["version"].(string); ok {
+		n.Version = version
+	}
+
 	if unsealKeysRaw, ok := iface["unseal_keys"].([]interface{}); ok {
 		n.UnsealKeys = nil
 		for _, keyRaw := range unsealKeysRaw {
@@ -84,6 +115,7 @@ var (
 	_ NodeConfigOpt = Seal(nil)
 	_ NodeConfigOpt = &DevConfig{}
 	_ NodeConfigOpt = Audit(nil)
+	_ NodeConfigOpt = &Telemetry{}
 )
 
 func ListNodes() ([]string, error) {
@@ -152,23 +184,8 @@ func BuildNode(name string, product string, opts ...NodeConfigOpt) (*Node, error
 		Type: product,
 	}
 
-	for index, opt := range opts {
-		switch tOpt := opt.(type) {
-		case Listener:
-			n.Config.Listeners = append(n.Config.Listeners, tOpt)
-		case Seal:
-			n.Config.Seals = append(n.Config.Seals, tOpt)
-		case Storage:
-			n.Config.Storage = tOpt
-		case *DevConfig:
-			n.Config.Dev = tOpt
-		case *UI:
-			n.Config.UI = tOpt
-		case Audit:
-			n.Config.Audits = append(n.Config.Audits, tOpt)
-		default:
-			return nil, fmt.Errorf("unknown type of node configuration option at index %d: %v (%T)", index, opt, opt)
-		}
+	if err := n.Config.ApplyOpts(opts...); err != nil {
+		return nil, err
 	}
 
 	if err := n.SaveConfig(); err != nil {
@@ -236,6 +253,17 @@ func (n *Node) buildExec() error {
 		return fmt.Errorf("failed to create node directory (%v): %w", directory, err)
 	}
 
+	if n.Adopted {
+		// Reuse the original command line and configuration of adopted
+		// nodes rather than generating our own.
+		if n.Exec == nil {
+			return fmt.Errorf("adopted node %v has no execution state", n.Name)
+		}
+
+		n.Exec.Directory = directory
+		return nil
+	}
+
 	addr, _, _, err := n.Config.GetConnectAddr(directory)
 	if err != nil {
 		return fmt.Errorf("failed to get connection address for node %v: %w", n.Name, err)
@@ -318,6 +346,9 @@ func (n *Node) Clean(force bool) error {
 		}
 	}
 
+	// Only devbao's own node directory is removed. For adopted nodes, this
+	// holds metadata alone: their configuration and storage live in foreign
+	// directories which are never touched.
 	directory := n.GetDirectory()
 	return os.RemoveAll(directory)
 }
@@ -327,19 +358,14 @@ func (n *Node) Resume() error {
 		return fmt.Errorf("failed to build execution environment: %w", err)
 	}
 
-	var err error
-	switch n.Type {
-	case "":
-		err = Exec(n.Exec)
-	case "bao":
-		err = ExecBao(n.Exec)
-	case "vault":
-		err = ExecVault(n.Exec)
-	default:
-		err = fmt.Errorf("unknown execution type: `%s`", n.Type)
+	binary, err := n.ServerBinary()
+	if err != nil {
+		return err
 	}
 
-	if err != nil {
+	n.Exec.Binary = binary
+	n.Version, _ = n.detectBinaryVersion()
+	if err := doExec(n.Exec); err != nil {
 		return err
 	}
 
@@ -347,6 +373,91 @@ func (n *Node) Resume() error {
 	return n.SaveConfig()
 }
 
+func (n *Node) Restart() error {
+	if err := n.Kill(); err != nil {
+		return fmt.Errorf("failed to stop node: %w", err)
+	}
+
+	if n.Exec != nil {
+		if err := n.Exec.WaitStopped(); err != nil {
+			return fmt.Errorf("failed waiting for node to stop: %w", err)
+		}
+	}
+
+	return n.resumeUnsealed()
+}
+
+// resumeUnsealed resumes the node, unsealing it with its stored keys when it
+// is not auto-unsealed.
+func (n *Node) resumeUnsealed() error {
+	if err := n.Resume(); err != nil {
+		return fmt.Errorf("failed to resume node: %w", err)
+	}
+
+	if n.Config.Dev != nil || len(n.UnsealKeys) == 0 || len(n.Config.Seals) > 0 {
+		return nil
+	}
+
+	if _, err := n.Unseal(); err != nil {
+		return fmt.Errorf("failed to unseal node: %w", err)
+	}
+
+	// TODO: use a client request with proper back-off to determine
+	// when the node is responding.
+	time.Sleep(500 * time.Millisecond)
+
+	return nil
+}
+
+func (n *Node) Reload() error {
+	if n.Exec == nil {
+		return fmt.Errorf("node has no execution state")
+	}
+
+	return n.Exec.Reload()
+}
+
+// RenderConfig builds the server's HCL configuration without persisting it
+// as the node's instance configuration.
+func (n *Node) RenderConfig() (string, error) {
+	if err := n.Validate(); err != nil {
+		return "", fmt.Errorf("failed to validate node definition: %w", err)
+	}
+
+	if n.Config.Dev != nil {
+		return "", nil
+	}
+
+	return n.Config.ToConfig(n.GetDirectory())
+}
+
+// PreviewConfig renders the server's HCL configuration like RenderConfig,
+// but without side effects: TLS material and storage and plugin directories
+// are written to a scratch directory, whose path is then replaced by the
+// node's directory.
+func (n *Node) PreviewConfig() (string, error) {
+	if err := n.Validate(); err != nil {
+		return "", fmt.Errorf("failed to validate node definition: %w", err)
+	}
+
+	if n.Config.Dev != nil {
+		return "", nil
+	}
+
+	staging, err := os.MkdirTemp("", "devbao-preview-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	config, err := n.Config.ToConfig(staging)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ReplaceAll(config, staging, n.GetDirectory()), nil
+}
+
 func (n *Node) LoadConfig() error {
 	directory := n.GetDirectory()
 	path := filepath.Join(directory, NodeJsonName)
@@ -625,6 +736,36 @@ func (n *Node) Unseal() (bool, error) {
 	return true, nil
 }
 
+// DisableAudits disables the given audit devices on the running node,
+// skipping those which are not enabled.
+func (n *Node) DisableAudits(audits []Audit) error {
+	client, err := n.GetClient()
+	if err != nil {
+		return fmt.Errorf("failed to get client for node: %w", err)
+	}
+
+	resp, err := client.Logical().Read("sys/audit")
+	if err != nil {
+		return fmt.Errorf("failed to list audit devices; %w", err)
+	}
+
+	for _, audit := range audits {
+		// Devices are listed with a trailing slash on their path.
+		name := audit.DeviceName()
+		_, present := resp.Data[name]
+		_, presentDir := resp.Data[name+"/"]
+		if !present && !presentDir {
+			continue
+		}
+
+		if _, err := client.Logical().Delete("sys/audit/" + name); err != nil {
+			return fmt.Errorf("failed to disable audit device %v: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
 func (n *Node) PostInitializeUnseal() error {
 	client, err := n.GetClient()
 	if err != nil {
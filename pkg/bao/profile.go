@@ -3,6 +3,7 @@ package bao
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/openbao/openbao/api/v2"
@@ -14,7 +15,7 @@ const (
 	UserpassProfile string = "userpass"
 )
 
-func ListProfiles() []string {
+func ListBuiltinProfiles() []string {
 	return []string{
 		PKIProfile,
 		TransitProfile,
@@ -22,7 +23,54 @@ func ListProfiles() []string {
 	}
 }
 
-func ProfileDescription(name string) string {
+// Profile is an available profile along with its description.
+type Profile struct {
+	Name        string
+	Description string
+}
+
+// ListProfiles returns the builtin profiles followed by user profiles,
+// loading each user profile once for its description; builtin profiles take
+// precedence over user profiles of the same name. When user profiles cannot
+// be listed, the builtin profiles are returned along with a warning.
+func ListProfiles() ([]*Profile, []string) {
+	builtin := ListBuiltinProfiles()
+
+	var profiles []*Profile
+	for _, name := range builtin {
+		profiles = append(profiles, &Profile{
+			Name:        name,
+			Description: builtinProfileDescription(name),
+		})
+	}
+
+	user, err := ListUserProfiles()
+	if err != nil {
+		return profiles, []string{err.Error()}
+	}
+
+	for _, name := range user {
+		if slices.Contains(builtin, name) {
+			continue
+		}
+
+		var description string
+		if profile, err := LoadUserProfile(name); err != nil {
+			description = fmt.Sprintf("(invalid: %v)", err)
+		} else {
+			description = profile.Description
+		}
+
+		profiles = append(profiles, &Profile{
+			Name:        name,
+			Description: description,
+		})
+	}
+
+	return profiles, nil
+}
+
+func builtinProfileDescription(name string) string {
 	switch name {
 	case PKIProfile:
 		return "enable a two-tier root & intermediate CA hierarchy"
@@ -44,7 +92,12 @@ func ProfileSetup(client *api.Client, profile string) ([]string, error) {
 	case UserpassProfile:
 		return ProfileUserpassMountSetup(client)
 	default:
-		return nil, fmt.Errorf("unknown profile to apply: %v", profile)
+		user, err := LoadUserProfile(profile)
+		if err != nil {
+			return nil, fmt.Errorf("unknown profile to apply: %v: %w", profile, err)
+		}
+
+		return user.SetupProfile(client)
 	}
 }
 
@@ -57,7 +110,12 @@ func ProfileRemove(client *api.Client, profile string) ([]string, error) {
 	case UserpassProfile:
 		return ProfileUserpassMountRemove(client)
 	default:
-		return nil, fmt.Errorf("unknown profile to apply: %v", profile)
+		user, err := LoadUserProfile(profile)
+		if err != nil {
+			return nil, fmt.Errorf("unknown profile to remove: %v: %w", profile, err)
+		}
+
+		return user.RemoveProfile(client)
 	}
 }
 
@@ -0,0 +1,438 @@
+package bao
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/hcl"
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	ProfileOpMount        = "mount"
+	ProfileOpUnmount      = "unmount"
+	ProfileOpAuth         = "auth"
+	ProfileOpAuthDisable  = "auth_disable"
+	ProfileOpPolicy       = "policy"
+	ProfileOpPolicyDelete = "policy_delete"
+	ProfileOpWrite        = "write"
+	ProfileOpDelete       = "delete"
+)
+
+func ListProfileOps() []string {
+	return []string{
+		ProfileOpMount,
+		ProfileOpUnmount,
+		ProfileOpAuth,
+		ProfileOpAuthDisable,
+		ProfileOpPolicy,
+		ProfileOpPolicyDelete,
+		ProfileOpWrite,
+		ProfileOpDelete,
+	}
+}
+
+// UserProfile is a profile defined in a file under ProfileBaseDirectory,
+// as an ordered list of operations applied on setup. Unless given
+// explicitly, removal runs the inverse of each setup operation in reverse
+// order. As HCL:
+//
+//	description = "KV mount with a reader policy"
+//
+//	setup "mount" {
+//	  path    = "kv"
+//	  type    = "kv"
+//	  options = { version = "2" }
+//	}
+//
+//	setup "policy" {
+//	  name   = "kv-reader"
+//	  policy = "path \"kv/data/*\" { capabilities = [\"read\"] }"
+//	}
+//
+//	setup "write" {
+//	  path = "kv/data/server"
+//	  data = { data = { address = "${.Address}" } }
+//	}
+//
+// or as JSON, with each operation naming its kind under `op`:
+//
+//	{"description": "...", "setup": [{"op": "mount", "path": "kv", "type": "kv"}]}
+//
+// String values, paths, and policies are templates with `${` and `}` as
+// delimiters, given the server's `.Address` and the response data of
+// earlier operations under `.Saved.<save>`.
+type UserProfile struct {
+	Name        string              `hcl:"-" json:"-"`
+	Description string              `hcl:"description" json:"description"`
+	Setup       []*ProfileOperation `hcl:"setup" json:"setup"`
+	Remove      []*ProfileOperation `hcl:"remove" json:"remove,omitempty"`
+}
+
+type ProfileOperation struct {
+	Op string `hcl:",key" json:"op"`
+
+	// Mount and auth operations.
+	Path            string            `hcl:"path" json:"path,omitempty"`
+	Type            string            `hcl:"type" json:"type,omitempty"`
+	Description     string            `hcl:"description" json:"description,omitempty"`
+	Options         map[string]string `hcl:"options" json:"options,omitempty"`
+	DefaultLeaseTTL string            `hcl:"default_lease_ttl" json:"default_lease_ttl,omitempty"`
+	MaxLeaseTTL     string            `hcl:"max_lease_ttl" json:"max_lease_ttl,omitempty"`
+
+	// Policy operations.
+	Name   string `hcl:"name" json:"name,omitempty"`
+	Policy string `hcl:"policy" json:"policy,omitempty"`
+
+	// Write operations; the response data is kept for later templates under
+	// Save.
+	Data map[string]interface{} `hcl:"data" json:"data,omitempty"`
+	Save string                 `hcl:"save" json:"save,omitempty"`
+
+	// bestEffort reports failures as warnings; it is set on deletes derived
+	// from writes, as not every written path can be deleted.
+	bestEffort bool
+}
+
+// profileTemplateData is available to templated values of operations.
+type profileTemplateData struct {
+	Address string
+	Saved   map[string]map[string]interface{}
+}
+
+func ProfileBaseDirectory() string {
+	usr, _ := user.Current()
+	dir := usr.HomeDir
+
+	return filepath.Join(dir, ".config/devbao/profiles")
+}
+
+// ListUserProfiles returns the names of profiles defined in .json or .hcl
+// files under ProfileBaseDirectory.
+func ListUserProfiles() ([]string, error) {
+	entries, err := os.ReadDir(ProfileBaseDirectory())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to list profiles (%v): %w", ProfileBaseDirectory(), err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".hcl") {
+			continue
+		}
+
+		names = append(names, strings.TrimSuffix(entry.Name(), ext))
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadUserProfile reads and validates the named user profile.
+func LoadUserProfile(name string) (*UserProfile, error) {
+	if name == "" || strings.ContainsRune(name, os.PathSeparator) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid profile name: `%v`", name)
+	}
+
+	var profile UserProfile
+	found := false
+	for _, ext := range []string{".hcl", ".json"} {
+		path := filepath.Join(ProfileBaseDirectory(), name+ext)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read profile (%v): %w", path, err)
+		}
+
+		if ext == ".json" {
+			err = json.Unmarshal(data, &profile)
+		} else {
+			err = hcl.Decode(&profile, string(data))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse profile (%v): %w", path, err)
+		}
+
+		found = true
+		break
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no %v.hcl or %v.json in %v", name, name, ProfileBaseDirectory())
+	}
+
+	profile.Name = name
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid profile %v: %w", name, err)
+	}
+
+	return &profile, nil
+}
+
+func (p *UserProfile) Validate() error {
+	if len(p.Setup) == 0 {
+		return fmt.Errorf("no setup operations")
+	}
+
+	for index, op := range append(append([]*ProfileOperation{}, p.Setup...), p.Remove...) {
+		// HCL decodes nested objects as lists of objects.
+		op.Data = normalizeHclMap(op.Data)
+
+		switch op.Op {
+		case ProfileOpMount, ProfileOpAuth:
+			if op.Path == "" || op.Type == "" {
+				return fmt.Errorf("operation %d (%v) requires path and type", index, op.Op)
+			}
+		case ProfileOpUnmount, ProfileOpAuthDisable, ProfileOpWrite, ProfileOpDelete:
+			if op.Path == "" {
+				return fmt.Errorf("operation %d (%v) requires path", index, op.Op)
+			}
+		case ProfileOpPolicy:
+			if op.Name == "" || op.Policy == "" {
+				return fmt.Errorf("operation %d (%v) requires name and policy", index, op.Op)
+			}
+		case ProfileOpPolicyDelete:
+			if op.Name == "" {
+				return fmt.Errorf("operation %d (%v) requires name", index, op.Op)
+			}
+		default:
+			return fmt.Errorf("operation %d has unknown type `%v`; valid types are %v", index, op.Op, strings.Join(ListProfileOps(), ", "))
+		}
+	}
+
+	return nil
+}
+
+func normalizeHclMap(data map[string]interface{}) map[string]interface{} {
+	for key, value := range data {
+		data[key] = normalizeHclValue(value)
+	}
+
+	return data
+}
+
+func normalizeHclValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case []map[string]interface{}:
+		if len(typed) == 1 {
+			return normalizeHclMap(typed[0])
+		}
+
+		var list []interface{}
+		for _, item := range typed {
+			list = append(list, normalizeHclMap(item))
+		}
+
+		return list
+	case map[string]interface{}:
+		return normalizeHclMap(typed)
+	case []interface{}:
+		for index, item := range typed {
+			typed[index] = normalizeHclValue(item)
+		}
+	}
+
+	return value
+}
+
+// RemoveOperations returns the profile's explicit removal operations or,
+// absent those, the inverse of its setup operations in reverse order.
+// Writes within mounts created by the profile are dropped along with the
+// mount rather than deleted; other writes are deleted on a best-effort
+// basis.
+func (p *UserProfile) RemoveOperations() []*ProfileOperation {
+	if len(p.Remove) > 0 {
+		return p.Remove
+	}
+
+	var mounts []string
+	for _, op := range p.Setup {
+		if op.Op == ProfileOpMount {
+			mounts = append(mounts, strings.TrimSuffix(op.Path, "/")+"/")
+		} else if op.Op == ProfileOpAuth {
+			mounts = append(mounts, "auth/"+strings.TrimSuffix(op.Path, "/")+"/")
+		}
+	}
+
+	var ops []*ProfileOperation
+	for index := len(p.Setup) - 1; index >= 0; index-- {
+		op := p.Setup[index]
+		switch op.Op {
+		case ProfileOpMount:
+			ops = append(ops, &ProfileOperation{Op: ProfileOpUnmount, Path: op.Path})
+		case ProfileOpAuth:
+			ops = append(ops, &ProfileOperation{Op: ProfileOpAuthDisable, Path: op.Path})
+		case ProfileOpPolicy:
+			ops = append(ops, &ProfileOperation{Op: ProfileOpPolicyDelete, Name: op.Name})
+		case ProfileOpWrite:
+			inMount := false
+			for _, mount := range mounts {
+				if strings.HasPrefix(op.Path, mount) {
+					inMount = true
+				}
+			}
+
+			if !inMount {
+				ops = append(ops, &ProfileOperation{Op: ProfileOpDelete, Path: op.Path, bestEffort: true})
+			}
+		}
+	}
+
+	return ops
+}
+
+func (p *UserProfile) SetupProfile(client *api.Client) ([]string, error) {
+	return p.run(client, p.Setup)
+}
+
+func (p *UserProfile) RemoveProfile(client *api.Client) ([]string, error) {
+	return p.run(client, p.RemoveOperations())
+}
+
+func (p *UserProfile) run(client *api.Client, ops []*ProfileOperation) ([]string, error) {
+	var warnings []string
+	data := &profileTemplateData{
+		Address: client.Address(),
+		Saved:   map[string]map[string]interface{}{},
+	}
+
+	for index, op := range ops {
+		opWarnings, err := op.apply(client, data)
+		if len(opWarnings) > 0 {
+			warnings = PrefixedAppend(warnings, fmt.Sprintf("from operation %d (%v):\n\t", index, op.Op), opWarnings...)
+		}
+		if err != nil && op.bestEffort {
+			warnings = append(warnings, fmt.Sprintf("operation %d (%v of %v) failed; continuing: %v", index, op.Op, op.Path, err))
+		} else if err != nil {
+			return warnings, fmt.Errorf("profile %v: operation %d (%v) failed: %w", p.Name, index, op.Op, err)
+		}
+	}
+
+	return warnings, nil
+}
+
+func renderProfileTemplate(text string, data *profileTemplateData) (string, error) {
+	if !strings.Contains(text, "${") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("").Delims("${", "}").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template `%v`: %w", text, err)
+	}
+
+	var result strings.Builder
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", fmt.Errorf("failed to render template `%v`: %w", text, err)
+	}
+
+	return result.String(), nil
+}
+
+func renderProfileValue(value interface{}, data *profileTemplateData) (interface{}, error) {
+	switch typed := value.(type) {
+	case string:
+		return renderProfileTemplate(typed, data)
+	case map[string]interface{}:
+		rendered := map[string]interface{}{}
+		for key, item := range typed {
+			var err error
+			if rendered[key], err = renderProfileValue(item, data); err != nil {
+				return nil, err
+			}
+		}
+
+		return rendered, nil
+	case []interface{}:
+		var rendered []interface{}
+		for _, item := range typed {
+			result, err := renderProfileValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+
+			rendered = append(rendered, result)
+		}
+
+		return rendered, nil
+	}
+
+	return value, nil
+}
+
+func (o *ProfileOperation) apply(client *api.Client, data *profileTemplateData) ([]string, error) {
+	path, err := renderProfileTemplate(o.Path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case ProfileOpMount, ProfileOpAuth:
+		input := &api.MountInput{
+			Type:        o.Type,
+			Description: o.Description,
+			Options:     o.Options,
+			Config: api.MountConfigInput{
+				DefaultLeaseTTL: o.DefaultLeaseTTL,
+				MaxLeaseTTL:     o.MaxLeaseTTL,
+			},
+		}
+
+		if o.Op == ProfileOpAuth {
+			return nil, client.Sys().EnableAuthWithOptions(path, input)
+		}
+
+		return nil, client.Sys().Mount(path, input)
+	case ProfileOpUnmount:
+		return nil, client.Sys().Unmount(path)
+	case ProfileOpAuthDisable:
+		return nil, client.Sys().DisableAuth(path)
+	case ProfileOpPolicy:
+		policy, err := renderProfileTemplate(o.Policy, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, client.Sys().PutPolicy(o.Name, policy)
+	case ProfileOpPolicyDelete:
+		return nil, client.Sys().DeletePolicy(o.Name)
+	case ProfileOpWrite:
+		body, err := renderProfileValue(o.Data, data)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Logical().Write(path, body.(map[string]interface{}))
+		if err != nil || resp == nil {
+			return nil, err
+		}
+
+		if o.Save != "" {
+			data.Saved[o.Save] = resp.Data
+		}
+
+		return resp.Warnings, nil
+	case ProfileOpDelete:
+		resp, err := client.Logical().Delete(path)
+		if err != nil || resp == nil {
+			return nil, err
+		}
+
+		return resp.Warnings, nil
+	}
+
+	return nil, fmt.Errorf("unknown operation type `%v`", o.Op)
+}
@@ -0,0 +1,343 @@
+package bao
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+const (
+	ProxyDirectoryName = "proxy"
+
+	proxyRefreshInterval = 250 * time.Millisecond
+)
+
+func (c *Cluster) proxyDirectory() string {
+	return filepath.Join(c.GetDirectory(), ProxyDirectoryName)
+}
+
+// ProxyRunning reports whether the cluster's fault-injection proxy process
+// is alive.
+func (c *Cluster) ProxyRunning() bool {
+	return c.Proxy != nil && c.Proxy.ValidateRunning() == nil
+}
+
+// StartProxy launches the fault-injection proxy for the cluster as a
+// background `devbao cluster proxy` process. The proxy listens on the
+// advertised cluster address of each member with ClusterProxy set and
+// forwards to its cluster listener.
+func (c *Cluster) StartProxy() error {
+	if c.ProxyRunning() {
+		return nil
+	}
+
+	var connectAddr string
+	for _, name := range c.Nodes {
+		node, err := LoadNode(name)
+		if err != nil {
+			return fmt.Errorf("error loading node %v: %w", name, err)
+		}
+
+		if !node.Config.ClusterProxy {
+			continue
+		}
+
+		_, connectAddr, err = node.Config.ClusterAddrs(node.GetDirectory())
+		if err != nil {
+			return err
+		}
+
+		break
+	}
+
+	if connectAddr == "" {
+		return fmt.Errorf("no members of cluster %v advertise a proxied cluster address", c.Name)
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find devbao binary: %w", err)
+	}
+
+	directory := c.proxyDirectory()
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return fmt.Errorf("failed to create proxy directory (%v): %w", directory, err)
+	}
+
+	c.Proxy = &ExecEnvironment{
+		Binary:    binary,
+		Args:      []string{"cluster", "proxy", c.Name},
+		Directory: directory,
+
+		ConnectAddress: connectAddr,
+	}
+
+	if err := doExec(c.Proxy); err != nil {
+		return fmt.Errorf("failed to start fault-injection proxy: %w", err)
+	}
+
+	return c.SaveConfig()
+}
+
+// StopProxy stops the cluster's fault-injection proxy, if running.
+func (c *Cluster) StopProxy() error {
+	if c.Proxy == nil {
+		return nil
+	}
+
+	if err := c.Proxy.Kill(); err != nil {
+		return fmt.Errorf("failed to stop fault-injection proxy: %w", err)
+	}
+
+	return c.Proxy.WaitStopped()
+}
+
+type proxyConn struct {
+	from   string
+	to     string
+	client net.Conn
+	server net.Conn
+}
+
+func (p *proxyConn) Close() {
+	_ = p.client.Close()
+	_ = p.server.Close()
+}
+
+type faultProxy struct {
+	cluster string
+
+	lock      sync.Mutex
+	faults    *Faults
+	pids      map[int32]string
+	listeners map[string]net.Listener
+	conns     map[*proxyConn]struct{}
+}
+
+// ServeProxy runs the fault-injection proxy for the named cluster in the
+// foreground. Membership and fault rules are re-read periodically; the
+// proxy exits once the cluster no longer exists.
+func ServeProxy(clusterName string) error {
+	p := &faultProxy{
+		cluster:   clusterName,
+		faults:    &Faults{},
+		pids:      map[int32]string{},
+		listeners: map[string]net.Listener{},
+		conns:     map[*proxyConn]struct{}{},
+	}
+
+	for {
+		if err := p.refresh(); err != nil {
+			return err
+		}
+
+		time.Sleep(proxyRefreshInterval)
+	}
+}
+
+func (p *faultProxy) refresh() error {
+	cluster, err := LoadCluster(p.cluster)
+	if err != nil {
+		return fmt.Errorf("error loading cluster: %w", err)
+	}
+
+	faults, err := cluster.LoadFaults()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[warning] keeping previous fault rules: %v\n", err)
+		faults = p.faults
+	}
+
+	pids := map[int32]string{}
+	targets := map[string]string{}
+	advertised := map[string]string{}
+	for _, name := range cluster.Nodes {
+		node, err := LoadNode(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[warning] error loading node %v: %v\n", name, err)
+			continue
+		}
+
+		if node.Exec != nil && node.Exec.Pid != 0 {
+			pids[int32(node.Exec.Pid)] = name
+		}
+
+		if !node.Config.ClusterProxy {
+			continue
+		}
+
+		listenAddr, advertiseAddr, err := node.Config.ClusterAddrs(node.GetDirectory())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[warning] error computing cluster address of node %v: %v\n", name, err)
+			continue
+		}
+
+		targets[name], _ = getConnectionAddr(listenAddr)
+		advertised[name], _ = getConnectionAddr(advertiseAddr)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.faults = faults
+	p.pids = pids
+
+	for name, listener := range p.listeners {
+		if _, present := targets[name]; !present {
+			_ = listener.Close()
+			delete(p.listeners, name)
+		}
+	}
+
+	for name, target := range targets {
+		if _, present := p.listeners[name]; present {
+			continue
+		}
+
+		listener, err := net.Listen("tcp", advertised[name])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[warning] failed to listen for node %v on %v: %v\n", name, advertised[name], err)
+			continue
+		}
+
+		fmt.Printf("proxying %v -> %v for node %v\n", advertised[name], target, name)
+		p.listeners[name] = listener
+		go p.accept(name, target, listener)
+	}
+
+	// Partitions apply to established connections as well; raft keeps
+	// its connections open indefinitely.
+	for conn := range p.conns {
+		if p.faults.Partitioned(conn.from, conn.to) {
+			conn.Close()
+			delete(p.conns, conn)
+		}
+	}
+
+	return nil
+}
+
+// sourceNode identifies the member which opened a connection by finding
+// the process owning its local end.
+func (p *faultProxy) sourceNode(conn net.Conn, pids map[int32]string) string {
+	host, portStr, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return ""
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return ""
+	}
+
+	for pid, name := range pids {
+		stats, err := psnet.ConnectionsPid("tcp", pid)
+		if err != nil {
+			continue
+		}
+
+		for _, stat := range stats {
+			if stat.Laddr.Port == uint32(port) && stat.Laddr.IP == host {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
+
+func (p *faultProxy) accept(to string, target string, listener net.Listener) {
+	for {
+		client, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go p.handle(to, target, client)
+	}
+}
+
+func (p *faultProxy) handle(to string, target string, client net.Conn) {
+	p.lock.Lock()
+	pids := p.pids
+	p.lock.Unlock()
+
+	from := p.sourceNode(client, pids)
+
+	p.lock.Lock()
+	faults := p.faults
+	p.lock.Unlock()
+
+	effect := faults.Effect(from, to)
+	if faults.Partitioned(from, to) || (effect.DropPercent > 0 && rand.Intn(100) < effect.DropPercent) {
+		_ = client.Close()
+		return
+	}
+
+	server, err := net.Dial("tcp", target)
+	if err != nil {
+		_ = client.Close()
+		return
+	}
+
+	conn := &proxyConn{from: from, to: to, client: client, server: server}
+
+	p.lock.Lock()
+	p.conns[conn] = struct{}{}
+	p.lock.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p.copy(server, client, from, to)
+		conn.Close()
+	}()
+	go func() {
+		defer wg.Done()
+		p.copy(client, server, to, from)
+		conn.Close()
+	}()
+	wg.Wait()
+
+	p.lock.Lock()
+	delete(p.conns, conn)
+	p.lock.Unlock()
+}
+
+// copy forwards data from src to dst, delaying each chunk according to the
+// current rules for the link.
+func (p *faultProxy) copy(dst net.Conn, src net.Conn, from string, to string) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			p.lock.Lock()
+			effect := p.faults.Effect(from, to)
+			p.lock.Unlock()
+
+			delay := effect.Latency
+			if effect.Jitter > 0 {
+				delay += time.Duration(rand.Int63n(2*int64(effect.Jitter))) - effect.Jitter
+			}
+
+			if delay > 0 {
+				time.Sleep(delay)
+			}
+
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package bao
+
+import (
+	"fmt"
+	"time"
+)
+
+// RollingOrder returns the standby members of the cluster in the order a
+// rolling operation visits them, non-voters before voters, along with the
+// leader, which is visited last.
+func (c *Cluster) RollingOrder() ([]*Node, *Node, error) {
+	leaderNode, _, err := c.GetLeader()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	voters, nonVoters, err := c.LoadMembers()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	standbys := nonVoters
+	for _, voter := range voters {
+		if voter.Name != leaderNode.Name {
+			standbys = append(standbys, voter)
+		}
+	}
+
+	return standbys, leaderNode, nil
+}
+
+// WaitForAutopilotHealthy polls the cluster's status until the named member
+// is running and unsealed, and autopilot considers both it and the cluster
+// as a whole healthy.
+func (c *Cluster) WaitForAutopilotHealthy(name string, start time.Time, timeout time.Duration, interval time.Duration) (*ClusterStatus, time.Duration, error) {
+	for {
+		status, err := c.Status()
+		if err != nil {
+			return nil, time.Since(start), err
+		}
+
+		var member *ClusterMemberStatus
+		for _, candidate := range status.Members {
+			if candidate.Name == name {
+				member = candidate
+			}
+		}
+
+		elapsed := time.Since(start)
+		if member == nil {
+			return status, elapsed, fmt.Errorf("node %v is no longer a member of cluster %v", name, c.Name)
+		}
+
+		if member.Running && !member.Sealed && member.Healthy && status.Healthy {
+			return status, elapsed, nil
+		}
+
+		if elapsed > timeout {
+			return status, elapsed, fmt.Errorf("autopilot did not report node %v and cluster %v healthy after %v", name, c.Name, elapsed)
+		}
+
+		time.Sleep(interval)
+	}
+}
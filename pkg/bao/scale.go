@@ -0,0 +1,212 @@
+package bao
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// memberIndex parses the index out of node names of the form used by
+// `cluster start`: `<cluster>-node-<index>`, optionally suffixed by `-nv`.
+func (c *Cluster) memberIndex(name string) (int, bool) {
+	prefix := c.Name + "-node-"
+	if !strings.HasPrefix(name, prefix) {
+		return 0, false
+	}
+
+	index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, prefix), "-nv"))
+	if err != nil {
+		return 0, false
+	}
+
+	return index, true
+}
+
+func tcpPort(listener *TCPListener) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(listener.Address)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to parse listener address (`%v`): %w", listener.Address, err)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to parse listener port (`%v`): %w", listener.Address, err)
+	}
+
+	return host, port, nil
+}
+
+// BuildNextMember derives the configuration of a new member from the given
+// template member: listeners move to the next free port block (in steps
+// of 100, as with `cluster start`) and the node is named after the next
+// free index. It keeps the template's type and pinned binary. The node is
+// saved but neither started nor joined.
+func (c *Cluster) BuildNextMember(template *Node, nonVoter bool) (*Node, error) {
+	if _, ok := template.Config.Storage.(*RaftStorage); !ok {
+		return nil, fmt.Errorf("template node %v does not use raft storage", template.Name)
+	}
+
+	nextIndex := len(c.Nodes)
+	maxPort := 0
+	for _, name := range c.Nodes {
+		if index, ok := c.memberIndex(name); ok && index >= nextIndex {
+			nextIndex = index + 1
+		}
+
+		member, err := LoadNode(name)
+		if err != nil {
+			return nil, fmt.Errorf("error loading node %v: %w", name, err)
+		}
+
+		for _, listener := range member.Config.Listeners {
+			if tcp, ok := listener.(*TCPListener); ok {
+				_, port, err := tcpPort(tcp)
+				if err != nil {
+					return nil, err
+				}
+
+				if port > maxPort {
+					maxPort = port
+				}
+			}
+		}
+	}
+
+	suffix := ""
+	if nonVoter {
+		suffix = "-nv"
+	}
+
+	var name string
+	for {
+		name = fmt.Sprintf("%v-node-%d%v", c.Name, nextIndex, suffix)
+		present, err := NodeExists(name)
+		if err != nil {
+			return nil, fmt.Errorf("error checking if node exists: %w", err)
+		}
+
+		if !present {
+			break
+		}
+
+		nextIndex += 1
+	}
+
+	cfg, err := template.Config.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy configuration of template node %v: %w", template.Name, err)
+	}
+
+	// Shift every TCP listener by the same offset, keeping the template's
+	// relative port layout.
+	offset := 0
+	for _, listener := range cfg.Listeners {
+		tcp, ok := listener.(*TCPListener)
+		if !ok {
+			return nil, fmt.Errorf("template node %v has a unix listener; unable to derive a new member from it", template.Name)
+		}
+
+		host, port, err := tcpPort(tcp)
+		if err != nil {
+			return nil, err
+		}
+
+		if offset == 0 {
+			offset = maxPort + 100 - port
+		}
+
+		tcp.Address = net.JoinHostPort(host, strconv.Itoa(port+offset))
+	}
+
+	storage := cfg.Storage.(*RaftStorage)
+	storage.Path = ""
+	storage.NodeID = name
+	storage.RetryJoin = nil
+	storage.RetryJoinAsNonVoter = false
+	if c.RetryJoin {
+		// The new member must know its peers before it first starts; the
+		// existing members learn of it once it is registered.
+		_, peers, err := c.retryJoinPeers()
+		if err != nil {
+			return nil, err
+		}
+
+		storage.RetryJoin = peers
+		storage.RetryJoinAsNonVoter = nonVoter
+	}
+
+	n := &Node{
+		Name:     name,
+		Type:     template.Type,
+		Config:   *cfg,
+		NonVoter: nonVoter,
+	}
+
+	// Run the same binary as the rest of the cluster rather than whichever
+	// is on the PATH.
+	if template.Binary != "" {
+		n.PinBinary(template.Binary)
+	}
+
+	if err := n.SaveConfig(); err != nil {
+		return nil, fmt.Errorf("failed saving initial configuration: %w", err)
+	}
+
+	return n, nil
+}
+
+// ScaleDownCandidates orders the given members by preference for removal:
+// stopped or sealed members first, then standbys, most recently added
+// first. The current leader is never included.
+func (c *Cluster) ScaleDownCandidates(members []*Node, status *ClusterStatus) []*Node {
+	states := map[string]*ClusterMemberStatus{}
+	for _, member := range status.Members {
+		states[member.Name] = member
+	}
+
+	var candidates []*Node
+	for _, member := range members {
+		if member.Name != status.Leader {
+			candidates = append(candidates, member)
+		}
+	}
+
+	healthy := func(node *Node) bool {
+		state := states[node.Name]
+		return state != nil && state.Running && !state.Sealed
+	}
+
+	position := map[string]int{}
+	for index, name := range c.Nodes {
+		position[name] = index
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if healthy(candidates[i]) != healthy(candidates[j]) {
+			return !healthy(candidates[i])
+		}
+
+		return position[candidates[i].Name] > position[candidates[j].Name]
+	})
+
+	return candidates
+}
+
+// HealthyVoters counts the voting members which are running and unsealed.
+func (c *Cluster) HealthyVoters(voters []*Node, status *ClusterStatus) int {
+	names := map[string]bool{}
+	for _, voter := range voters {
+		names[voter.Name] = true
+	}
+
+	count := 0
+	for _, member := range status.Members {
+		if names[member.Name] && member.Running && !member.Sealed {
+			count += 1
+		}
+	}
+
+	return count
+}
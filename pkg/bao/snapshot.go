@@ -0,0 +1,272 @@
+package bao
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	SnapshotDirName    = "snapshots"
+	SnapshotFileSuffix = ".snap"
+	SnapshotMetaSuffix = ".json"
+
+	snapshotTimeFormat = "20060102T150405.000Z"
+)
+
+// Snapshot describes a raft snapshot saved by devbao, stored alongside it.
+type Snapshot struct {
+	Name    string    `json:"name"`
+	Time    time.Time `json:"time"`
+	Node    string    `json:"node"`
+	Version string    `json:"version"`
+	Index   uint64    `json:"index"`
+	Term    uint64    `json:"term"`
+	Size    int64     `json:"size"`
+}
+
+// SnapshotStore is a directory of raft snapshots belonging to a node or to
+// a cluster.
+type SnapshotStore struct {
+	Directory string
+}
+
+func (n *Node) Snapshots() *SnapshotStore {
+	return &SnapshotStore{Directory: filepath.Join(n.GetDirectory(), SnapshotDirName)}
+}
+
+func (c *Cluster) Snapshots() *SnapshotStore {
+	return &SnapshotStore{Directory: filepath.Join(c.GetDirectory(), SnapshotDirName)}
+}
+
+func (s *SnapshotStore) path(name string) string {
+	return filepath.Join(s.Directory, name+SnapshotFileSuffix)
+}
+
+func (s *SnapshotStore) metaPath(name string) string {
+	return filepath.Join(s.Directory, name+SnapshotMetaSuffix)
+}
+
+// List returns the store's snapshots, oldest first.
+func (s *SnapshotStore) List() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(s.Directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error listing snapshot directory (`%v`): %w", s.Directory, err)
+	}
+
+	var results []*Snapshot
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), SnapshotMetaSuffix)
+		if entry.IsDir() || !found {
+			continue
+		}
+
+		snapshot, err := s.Load(name)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, snapshot)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Time.Before(results[j].Time)
+	})
+
+	return results, nil
+}
+
+func (s *SnapshotStore) Load(name string) (*Snapshot, error) {
+	path := s.metaPath(name)
+	metaFile, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot metadata (`%v`) for reading: %w", path, err)
+	}
+
+	defer metaFile.Close()
+
+	var snapshot Snapshot
+	if err := json.NewDecoder(metaFile).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot metadata: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// Save takes a raft snapshot through the given node's client and stores it
+// along with its metadata.
+func (s *SnapshotStore) Save(node string, client *api.Client) (*Snapshot, error) {
+	if err := os.MkdirAll(s.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory (%v): %w", s.Directory, err)
+	}
+
+	now := time.Now().UTC()
+	snapshot := &Snapshot{
+		Name: now.Format(snapshotTimeFormat),
+		Time: now,
+		Node: node,
+	}
+
+	for index := 1; ; index++ {
+		if _, err := os.Stat(s.metaPath(snapshot.Name)); os.IsNotExist(err) {
+			break
+		}
+
+		snapshot.Name = fmt.Sprintf("%v-%d", now.Format(snapshotTimeFormat), index)
+	}
+
+	if status, err := client.Sys().SealStatus(); err == nil {
+		snapshot.Version = status.Version
+	}
+
+	// Write to a temporary file first so that a failed snapshot is never
+	// listed.
+	path := s.path(snapshot.Name)
+	tmpPath := path + ".tmp"
+	snapFile, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file (`%v`) for writing: %w", tmpPath, err)
+	}
+
+	defer os.Remove(tmpPath)
+	defer snapFile.Close()
+
+	if err := client.Sys().RaftSnapshot(snapFile); err != nil {
+		return nil, fmt.Errorf("failed to take raft snapshot from node %v: %w", node, err)
+	}
+
+	if _, err := snapFile.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind snapshot file: %w", err)
+	}
+
+	snapshot.Index, snapshot.Term, err = readSnapshotMeta(snapFile)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := snapFile.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat snapshot file: %w", err)
+	}
+
+	snapshot.Size = info.Size()
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return nil, fmt.Errorf("failed to move snapshot into place: %w", err)
+	}
+
+	metaFile, err := os.OpenFile(s.metaPath(snapshot.Name), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot metadata (`%v`) for writing: %w", s.metaPath(snapshot.Name), err)
+	}
+
+	defer metaFile.Close()
+
+	if err := json.NewEncoder(metaFile).Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot metadata: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// Restore installs the named snapshot through the given client, which must
+// be that of the active node. With force, the snapshot is installed even
+// if it was taken from a cluster with different keys (`snapshot-force`).
+func (s *SnapshotStore) Restore(name string, client *api.Client, force bool) error {
+	if _, err := s.Load(name); err != nil {
+		return err
+	}
+
+	path := s.path(name)
+	snapFile, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot file (`%v`) for reading: %w", path, err)
+	}
+
+	defer snapFile.Close()
+
+	if err := client.Sys().RaftSnapshotRestore(snapFile, force); err != nil {
+		return fmt.Errorf("failed to restore raft snapshot %v: %w", name, err)
+	}
+
+	return nil
+}
+
+func (s *SnapshotStore) Remove(name string) error {
+	if err := os.Remove(s.path(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove snapshot %v: %w", name, err)
+	}
+
+	return os.Remove(s.metaPath(name))
+}
+
+// Prune removes all but the keep most recent snapshots, returning those
+// removed.
+func (s *SnapshotStore) Prune(keep int) ([]*Snapshot, error) {
+	snapshots, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if keep <= 0 || len(snapshots) <= keep {
+		return nil, nil
+	}
+
+	removed := snapshots[:len(snapshots)-keep]
+	for _, snapshot := range removed {
+		if err := s.Remove(snapshot.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return removed, nil
+}
+
+// readSnapshotMeta reads the raft index and term from the meta.json entry
+// of a snapshot archive.
+func readSnapshotMeta(r io.Reader) (uint64, uint64, error) {
+	uncompressed, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+
+	defer uncompressed.Close()
+
+	archive := tar.NewReader(uncompressed)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return 0, 0, fmt.Errorf("snapshot archive has no meta.json")
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read snapshot archive: %w", err)
+		}
+
+		if header.Name != "meta.json" {
+			continue
+		}
+
+		var meta struct {
+			Index uint64
+			Term  uint64
+		}
+		if err := json.NewDecoder(archive).Decode(&meta); err != nil {
+			return 0, 0, fmt.Errorf("failed to unmarshal snapshot meta.json: %w", err)
+		}
+
+		return meta.Index, meta.Term, nil
+	}
+}
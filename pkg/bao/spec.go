@@ -0,0 +1,390 @@
+package bao
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+)
+
+// Spec declaratively describes a set of nodes and clusters. It may be
+// written as HCL:
+//
+//	node "transit" {
+//	  storage   = "raft"
+//	  listeners = ["tcp:127.0.0.1:8100"]
+//	  profiles  = ["transit"]
+//	}
+//
+//	cluster "prod" {
+//	  count        = 3
+//	  listen       = "127.0.0.1"
+//	  transit_seal = "transit"
+//	  profiles     = ["pki", "userpass"]
+//	}
+//
+// or as the equivalent JSON, keyed by block type and then name.
+type Spec struct {
+	Nodes    []*SpecNode    `hcl:"node" json:"node,omitempty"`
+	Clusters []*SpecCluster `hcl:"cluster" json:"cluster,omitempty"`
+}
+
+// SpecResource is a single named node or cluster within a Spec.
+type SpecResource interface {
+	GetName() string
+	Dependencies() []string
+}
+
+var (
+	_ SpecResource = &SpecNode{}
+	_ SpecResource = &SpecCluster{}
+)
+
+type SpecNode struct {
+	Name string `hcl:",key" json:"-"`
+	Type string `hcl:"type" json:"type,omitempty"`
+
+	// Dev-mode nodes use Address and Token; other nodes use Listeners and
+	// Storage and are automatically initialized and unsealed.
+	Dev     bool   `hcl:"dev" json:"dev,omitempty"`
+	Address string `hcl:"address" json:"address,omitempty"`
+	Token   string `hcl:"token" json:"token,omitempty"`
+
+	Listeners []string `hcl:"listeners" json:"listeners,omitempty"`
+	Storage   string   `hcl:"storage" json:"storage,omitempty"`
+
+	// Seals are seal URIs as accepted by `node start -seals`; TransitSeal
+	// names another node in the spec whose transit profile seals this one.
+	Seals       []string `hcl:"seals" json:"seals,omitempty"`
+	TransitSeal string   `hcl:"transit_seal" json:"transit_seal,omitempty"`
+
+	Profiles  []string `hcl:"profiles" json:"profiles,omitempty"`
+	Audit     bool     `hcl:"audit" json:"audit,omitempty"`
+	UI        bool     `hcl:"ui" json:"ui,omitempty"`
+	DependsOn []string `hcl:"depends_on" json:"depends_on,omitempty"`
+}
+
+func (s *SpecNode) GetName() string {
+	return s.Name
+}
+
+func (s *SpecNode) Dependencies() []string {
+	if s.TransitSeal != "" {
+		return append([]string{s.TransitSeal}, s.DependsOn...)
+	}
+
+	return s.DependsOn
+}
+
+type SpecCluster struct {
+	Name     string `hcl:",key" json:"-"`
+	NodeType string `hcl:"node_type" json:"node_type,omitempty"`
+
+	Count     int    `hcl:"count" json:"count,omitempty"`
+	NonVoters int    `hcl:"non_voters" json:"non_voters,omitempty"`
+	Listen    string `hcl:"listen" json:"listen,omitempty"`
+	Port      int    `hcl:"port" json:"port,omitempty"`
+	RetryJoin bool   `hcl:"retry_join" json:"retry_join,omitempty"`
+
+	FaultProxy bool `hcl:"fault_proxy" json:"fault_proxy,omitempty"`
+
+	Seals       []string `hcl:"seals" json:"seals,omitempty"`
+	TransitSeal string   `hcl:"transit_seal" json:"transit_seal,omitempty"`
+
+	Profiles  []string `hcl:"profiles" json:"profiles,omitempty"`
+	DependsOn []string `hcl:"depends_on" json:"depends_on,omitempty"`
+}
+
+func (s *SpecCluster) GetName() string {
+	return s.Name
+}
+
+func (s *SpecCluster) Dependencies() []string {
+	if s.TransitSeal != "" {
+		return append([]string{s.TransitSeal}, s.DependsOn...)
+	}
+
+	return s.DependsOn
+}
+
+// LoadSpec reads and validates a spec from the given HCL or JSON file.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec (%v): %w", path, err)
+	}
+
+	return ParseSpec(string(data))
+}
+
+// ParseSpec decodes a spec from HCL or JSON, filling in defaults and
+// validating it.
+func ParseSpec(contents string) (*Spec, error) {
+	var spec Spec
+	if err := hcl.Decode(&spec, contents); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	for _, node := range spec.Nodes {
+		if node.Dev {
+			if node.Address == "" {
+				node.Address = "127.0.0.1:8200"
+			}
+
+			if node.Token == "" {
+				node.Token = "devroot"
+			}
+		} else {
+			if len(node.Listeners) == 0 {
+				node.Listeners = []string{"tcp:0.0.0.0:8200"}
+			}
+
+			if node.Storage == "" {
+				node.Storage = "raft"
+			}
+		}
+	}
+
+	for _, cluster := range spec.Clusters {
+		if cluster.Count == 0 {
+			cluster.Count = 3
+		}
+
+		if cluster.Listen == "" {
+			cluster.Listen = "0.0.0.0"
+		}
+
+		if cluster.Port == 0 {
+			cluster.Port = 8200
+		}
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+func (s *Spec) Validate() error {
+	nodes := map[string]*SpecNode{}
+	names := map[string]bool{}
+
+	for _, node := range s.Nodes {
+		if names[node.Name] {
+			return fmt.Errorf("duplicate resource name in spec: %v", node.Name)
+		}
+
+		names[node.Name] = true
+		nodes[node.Name] = node
+
+		if node.Dev && (len(node.Listeners) > 0 || node.Storage != "") {
+			return fmt.Errorf("node %v: dev mode nodes cannot specify listeners or storage", node.Name)
+		}
+
+		if !node.Dev && (node.Address != "" || node.Token != "") {
+			return fmt.Errorf("node %v: address and token are only valid for dev mode nodes", node.Name)
+		}
+
+		switch node.Storage {
+		case "", "raft", "file", "inmem":
+		default:
+			return fmt.Errorf("node %v: unknown storage `%v`; supported values are `raft`, `file`, or `inmem`", node.Name, node.Storage)
+		}
+
+		if node.Dev && (len(node.Seals) > 0 || node.TransitSeal != "") {
+			return fmt.Errorf("node %v: dev mode nodes cannot be sealed", node.Name)
+		}
+	}
+
+	for _, cluster := range s.Clusters {
+		if names[cluster.Name] {
+			return fmt.Errorf("duplicate resource name in spec: %v", cluster.Name)
+		}
+
+		names[cluster.Name] = true
+
+		if cluster.Count < 1 {
+			return fmt.Errorf("cluster %v: required to have at least one node; got %v", cluster.Name, cluster.Count)
+		}
+
+		if cluster.NonVoters < 0 || cluster.NonVoters >= cluster.Count {
+			return fmt.Errorf("cluster %v: required to have at least one voting node; have %v non-voters of %v nodes", cluster.Name, cluster.NonVoters, cluster.Count)
+		}
+	}
+
+	for _, resource := range s.Resources() {
+		for _, dep := range resource.Dependencies() {
+			if !names[dep] {
+				return fmt.Errorf("%v: unknown dependency `%v`", resource.GetName(), dep)
+			}
+		}
+	}
+
+	for _, resource := range s.Resources() {
+		var transit string
+		switch tRes := resource.(type) {
+		case *SpecNode:
+			transit = tRes.TransitSeal
+		case *SpecCluster:
+			transit = tRes.TransitSeal
+		}
+
+		if transit != "" && nodes[transit] == nil {
+			return fmt.Errorf("%v: transit_seal must reference a node; `%v` is a cluster", resource.GetName(), transit)
+		}
+
+		if transit != "" && nodes[transit].Dev {
+			return fmt.Errorf("%v: transit_seal node `%v` runs in dev mode, whose auto-unseal key is recreated on every resume; use a node with storage instead", resource.GetName(), transit)
+		}
+	}
+
+	if err := s.validatePorts(); err != nil {
+		return err
+	}
+
+	if _, err := s.Order(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// specPortClaim is a TCP port one resource of a spec listens on.
+type specPortClaim struct {
+	resource string
+	host     string
+}
+
+// validatePorts rejects specs whose resources would listen on the same
+// ports, such as two nodes left at the default address. Each API port
+// also claims the cluster port above it, and the proxy port above that
+// for clusters with a fault-injection proxy.
+func (s *Spec) validatePorts() error {
+	claims := map[int][]specPortClaim{}
+	claim := func(resource string, address string, extra int) error {
+		host, portRaw, err := net.SplitHostPort(address)
+		if err != nil {
+			return fmt.Errorf("%v: failed to parse listen address (`%v`): %w", resource, address, err)
+		}
+
+		port, err := strconv.Atoi(portRaw)
+		if err != nil {
+			return fmt.Errorf("%v: failed to parse listen port (`%v`): %w", resource, portRaw, err)
+		}
+
+		for offset := 0; offset <= extra; offset++ {
+			for _, other := range claims[port+offset] {
+				if other.resource == resource {
+					continue
+				}
+
+				if other.host == host || isWildcardHost(other.host) || isWildcardHost(host) {
+					return fmt.Errorf("%v and %v both listen on port %d; give them distinct addresses in the spec", other.resource, resource, port+offset)
+				}
+			}
+
+			claims[port+offset] = append(claims[port+offset], specPortClaim{resource: resource, host: host})
+		}
+
+		return nil
+	}
+
+	for _, node := range s.Nodes {
+		if node.Dev {
+			if err := claim(node.Name, node.Address, 1); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		for _, listener := range node.Listeners {
+			if !strings.HasPrefix(listener, "tcp:") {
+				continue
+			}
+
+			if err := claim(node.Name, strings.TrimPrefix(listener, "tcp:"), 1); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, cluster := range s.Clusters {
+		extra := 1
+		if cluster.FaultProxy {
+			extra = 2
+		}
+
+		for index := 0; index < cluster.Count; index++ {
+			address := net.JoinHostPort(cluster.Listen, strconv.Itoa(cluster.Port+index*100))
+			if err := claim(cluster.Name, address, extra); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func isWildcardHost(host string) bool {
+	return host == "" || host == "0.0.0.0" || host == "::"
+}
+
+// Resources lists all nodes and then all clusters, in declaration order.
+func (s *Spec) Resources() []SpecResource {
+	var results []SpecResource
+	for _, node := range s.Nodes {
+		results = append(results, node)
+	}
+
+	for _, cluster := range s.Clusters {
+		results = append(results, cluster)
+	}
+
+	return results
+}
+
+// Order returns the resources such that each appears after all of its
+// dependencies; otherwise, declaration order is preserved.
+func (s *Spec) Order() ([]SpecResource, error) {
+	remaining := s.Resources()
+	done := map[string]bool{}
+
+	var results []SpecResource
+	for len(remaining) > 0 {
+		var blocked []SpecResource
+		for _, resource := range remaining {
+			ready := true
+			for _, dep := range resource.Dependencies() {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				results = append(results, resource)
+				done[resource.GetName()] = true
+			} else {
+				blocked = append(blocked, resource)
+			}
+		}
+
+		if len(blocked) == len(remaining) {
+			var names []string
+			for _, resource := range blocked {
+				names = append(names, resource.GetName())
+			}
+
+			return nil, fmt.Errorf("dependency cycle between: %v", strings.Join(names, ", "))
+		}
+
+		remaining = blocked
+	}
+
+	return results, nil
+}
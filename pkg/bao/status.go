@@ -0,0 +1,245 @@
+package bao
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	HAStateActive      = "active"
+	HAStateStandby     = "standby"
+	HAStatePerfStandby = "perf-standby"
+	HAStateSealed      = "sealed"
+	HAStateStopped     = "stopped"
+	HAStateUnknown     = "unknown"
+)
+
+// ClusterMemberStatus describes the process, seal, and raft state of a
+// single member of a cluster.
+type ClusterMemberStatus struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Pid     int    `json:"pid,omitempty"`
+	Running bool   `json:"running"`
+
+	Initialized bool   `json:"initialized"`
+	Sealed      bool   `json:"sealed"`
+	HAState     string `json:"ha_state"`
+
+	NodeID    string `json:"node_id,omitempty"`
+	Voter     bool   `json:"voter"`
+	LastIndex uint64 `json:"last_index"`
+	LastTerm  uint64 `json:"last_term"`
+	Healthy   bool   `json:"healthy"`
+
+	Binary  string `json:"binary,omitempty"`
+	Version string `json:"version,omitempty"`
+
+	Errors []string `json:"errors,omitempty"`
+}
+
+type ClusterStatus struct {
+	Name             string                 `json:"name"`
+	Leader           string                 `json:"leader,omitempty"`
+	Healthy          bool                   `json:"healthy"`
+	FailureTolerance int                    `json:"failure_tolerance"`
+	Members          []*ClusterMemberStatus `json:"members"`
+
+	// Upgrade is autopilot's view of an in-progress upgrade migration, when
+	// the server reports one.
+	Upgrade *api.AutopilotUpgrade `json:"upgrade,omitempty"`
+
+	Errors []string `json:"errors,omitempty"`
+}
+
+func (s *ClusterMemberStatus) addError(err error) {
+	s.Errors = append(s.Errors, err.Error())
+}
+
+func (s *ClusterStatus) addError(err error) {
+	s.Errors = append(s.Errors, err.Error())
+}
+
+// nodeHealth reads sys/health without treating standby or sealed nodes as
+// errors, returning the raw response so that fields missing from
+// api.HealthResponse (such as performance_standby) are available.
+func nodeHealth(client *api.Client) (map[string]interface{}, error) {
+	resp, err := client.Logical().ReadRawWithData("sys/health", map[string][]string{
+		"uninitcode":      {"299"},
+		"sealedcode":      {"299"},
+		"standbycode":     {"299"},
+		"perfstandbyok":   {"true"},
+		"perfstandbycode": {"299"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	if err := resp.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func memberStatus(node *Node) *ClusterMemberStatus {
+	status := &ClusterMemberStatus{
+		Name:    node.Name,
+		HAState: HAStateStopped,
+	}
+
+	if storage, ok := node.Config.Storage.(*RaftStorage); ok {
+		status.NodeID = storage.NodeID
+	}
+
+	// The version autopilot reports, when available, takes precedence over
+	// the version of the binary devbao last started.
+	status.Binary = node.Binary
+	if status.Binary == "" && node.Exec != nil {
+		status.Binary = node.Exec.Binary
+	}
+
+	status.Version, _ = node.BinaryVersion()
+
+	addr, _, err := node.GetConnectAddr()
+	if err != nil {
+		status.addError(err)
+	}
+	status.Address = addr
+
+	if node.Exec == nil || node.Exec.ValidateRunning() != nil {
+		return status
+	}
+
+	status.Pid = node.Exec.Pid
+	status.Running = true
+	status.HAState = HAStateUnknown
+
+	client, err := node.GetClient()
+	if err != nil {
+		status.addError(err)
+		return status
+	}
+
+	health, err := nodeHealth(client)
+	if err != nil {
+		status.addError(fmt.Errorf("error reading health: %w", err))
+		return status
+	}
+
+	status.Initialized, _ = health["initialized"].(bool)
+	status.Sealed, _ = health["sealed"].(bool)
+	standby, _ := health["standby"].(bool)
+	perfStandby, _ := health["performance_standby"].(bool)
+
+	switch {
+	case status.Sealed:
+		status.HAState = HAStateSealed
+	case perfStandby:
+		status.HAState = HAStatePerfStandby
+	case standby:
+		status.HAState = HAStateStandby
+	default:
+		status.HAState = HAStateActive
+	}
+
+	return status
+}
+
+// Status gathers the state of every member of the cluster. Members which
+// are stopped or unreachable are still reported; failures to read any part
+// of the state are recorded as errors on the status rather than returned.
+func (c *Cluster) Status() (*ClusterStatus, error) {
+	status := &ClusterStatus{
+		Name: c.Name,
+	}
+
+	for index, name := range c.Nodes {
+		node, err := LoadNode(name)
+		if err != nil {
+			return nil, fmt.Errorf("error loading node %d / %v: %w", index, name, err)
+		}
+
+		status.Members = append(status.Members, memberStatus(node))
+	}
+
+	leaderNode, leaderClient, err := c.GetLeader()
+	if err != nil {
+		status.addError(err)
+		return status, nil
+	}
+
+	status.Leader = leaderNode.Name
+
+	// Map API addresses to cluster addresses, so that members without an
+	// explicit node_id can be matched against the raft configuration.
+	clusterAddrs := map[string]string{}
+	haResp, err := leaderClient.Logical().Read("sys/ha-status")
+	if err != nil {
+		status.addError(fmt.Errorf("error reading ha-status: %w", err))
+	} else if haResp != nil {
+		nodes, _ := haResp.Data["nodes"].([]interface{})
+		for _, nodeRaw := range nodes {
+			node := nodeRaw.(map[string]interface{})
+			apiAddr, _ := node["api_address"].(string)
+			clusterAddr, _ := node["cluster_address"].(string)
+			clusterAddrs[apiAddr] = clusterAddr
+		}
+	}
+
+	cfgResp, err := leaderClient.Logical().Read("sys/storage/raft/configuration")
+	if err != nil {
+		status.addError(fmt.Errorf("error reading raft configuration: %w", err))
+	} else if cfgResp != nil {
+		cfg := cfgResp.Data["config"].(map[string]interface{})
+		servers := cfg["servers"].([]interface{})
+		for _, member := range status.Members {
+			clusterAddr := clusterAddrs[member.Address]
+			for _, serverRaw := range servers {
+				server := serverRaw.(map[string]interface{})
+				nodeId, _ := server["node_id"].(string)
+				addr, _ := server["address"].(string)
+
+				matches := nodeId == member.NodeID
+				if member.NodeID == "" {
+					matches = clusterAddr != "" && strings.Contains(clusterAddr, addr)
+				}
+
+				if matches {
+					member.NodeID = nodeId
+					member.Voter, _ = server["voter"].(bool)
+					break
+				}
+			}
+		}
+	}
+
+	state, err := leaderClient.Sys().RaftAutopilotState()
+	if err != nil {
+		status.addError(fmt.Errorf("error reading autopilot state: %w", err))
+	} else if state != nil {
+		status.Healthy = state.Healthy
+		status.FailureTolerance = state.FailureTolerance
+		status.Upgrade = state.Upgrade
+
+		for _, member := range status.Members {
+			server, present := state.Servers[member.NodeID]
+			if member.NodeID == "" || !present {
+				continue
+			}
+
+			member.LastIndex = server.LastIndex
+			member.LastTerm = server.LastTerm
+			member.Healthy = server.Healthy
+			if server.Version != "" {
+				member.Version = server.Version
+			}
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,617 @@
+package bao
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openbao/openbao/api/v2"
+)
+
+const (
+	TerraformMainName      = "main.tf"
+	TerraformVariablesName = "variables.tf"
+)
+
+// Fields of PKI roles, transit keys, and userpass users carried over into
+// the Terraform provider's resources, which share the API's field names.
+var (
+	terraformPKIRoleFields = []string{
+		"issuer_ref", "ttl", "max_ttl", "not_before_duration",
+		"allow_localhost", "allowed_domains", "allowed_domains_template",
+		"allow_bare_domains", "allow_subdomains", "allow_glob_domains",
+		"allow_any_name", "enforce_hostnames", "allow_ip_sans",
+		"allowed_uri_sans", "allowed_other_sans", "server_flag",
+		"client_flag", "code_signing_flag", "email_protection_flag",
+		"key_type", "key_bits", "key_usage", "ext_key_usage",
+		"use_csr_common_name", "use_csr_sans", "require_cn", "ou",
+		"organization", "country", "locality", "province", "street_address",
+		"postal_code", "generate_lease", "no_store",
+		"basic_constraints_valid_for_non_ca",
+	}
+
+	// The provider takes these PKI role durations as strings.
+	terraformPKIRoleStringFields = map[string]bool{
+		"ttl":                 true,
+		"max_ttl":             true,
+		"not_before_duration": true,
+	}
+
+	terraformTransitKeyFields = []string{
+		"type", "deletion_allowed", "exportable", "allow_plaintext_backup",
+		"derived", "convergent_encryption", "auto_rotate_period",
+		"min_decryption_version", "min_encryption_version",
+	}
+
+	terraformUserpassFields = []string{
+		"token_policies", "token_ttl", "token_max_ttl",
+		"token_explicit_max_ttl", "token_period", "token_type",
+		"token_bound_cidrs", "token_num_uses", "token_no_default_policy",
+	}
+
+	terraformInvalidName = regexp.MustCompile(`[^a-z0-9]+`)
+	terraformIdentifier  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+)
+
+// hclAttr is an attribute of a Terraform block with its value already
+// rendered as an HCL expression.
+// Attributes without a key hold a nested block, rendered by hclBlock.
+type hclAttr struct {
+	Key   string
+	Value string
+}
+
+// terraformExport accumulates the resources and variables rendered from a
+// node's live state.
+type terraformExport struct {
+	client *api.Client
+
+	names     map[string]bool
+	mountRefs map[string]string
+
+	resources []string
+	variables []string
+	warnings  []string
+}
+
+// ExportTerraform introspects the node's mounts, auth methods, policies,
+// PKI roles and issuers, transit keys, and userpass users, and renders
+// them as resources of the Terraform Vault provider (which also manages
+// OpenBao). Secrets which cannot be read back, such as userpass passwords
+// and issuer private keys, become sensitive variables. It returns the
+// contents of main.tf and variables.tf, along with warnings about objects
+// which could not be exported.
+func (n *Node) ExportTerraform() (string, string, []string, error) {
+	client, err := n.GetClient()
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to get client for node %v: %w", n.Name, err)
+	}
+
+	addr, _, err := n.GetConnectAddr()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	t := &terraformExport{
+		client:    client,
+		names:     map[string]bool{},
+		mountRefs: map[string]string{},
+	}
+
+	t.variable("vault_address", "Address of the server to configure.", false, hclString(addr))
+	t.variable("vault_token", "Token used to configure the server.", true, "")
+
+	if err := t.exportMounts(); err != nil {
+		return "", "", nil, err
+	}
+
+	if err := t.exportAuths(); err != nil {
+		return "", "", nil, err
+	}
+
+	if err := t.exportPolicies(); err != nil {
+		return "", "", nil, err
+	}
+
+	main := `terraform {
+  required_providers {
+    vault = {
+      source = "hashicorp/vault"
+    }
+  }
+}
+
+provider "vault" {
+  address = var.vault_address
+  token   = var.vault_token
+}
+`
+	for _, resource := range t.resources {
+		main += "\n" + resource
+	}
+
+	return main, strings.Join(t.variables, "\n"), t.warnings, nil
+}
+
+// name returns a unique Terraform identifier for a resource of the given
+// type, derived from the given parts.
+func (t *terraformExport) name(resourceType string, parts ...string) string {
+	base := strings.Trim(terraformInvalidName.ReplaceAllString(strings.ToLower(strings.Join(parts, "_")), "_"), "_")
+	if base == "" || (base[0] >= '0' && base[0] <= '9') {
+		base = "_" + base
+	}
+
+	name := base
+	for index := 2; t.names[resourceType+"."+name]; index++ {
+		name = fmt.Sprintf("%v_%d", base, index)
+	}
+
+	t.names[resourceType+"."+name] = true
+	return name
+}
+
+func (t *terraformExport) resource(resourceType string, name string, comment string, attrs []hclAttr) {
+	var resource string
+	if comment != "" {
+		resource += "# " + comment + "\n"
+	}
+
+	resource += fmt.Sprintf("resource %v %v {\n%v}\n", hclString(resourceType), hclString(name), renderHclAttrs(attrs, "  "))
+	t.resources = append(t.resources, resource)
+}
+
+// variable declares an input variable, returning a reference to it.
+func (t *terraformExport) variable(name string, description string, sensitive bool, defaultValue string) string {
+	attrs := []hclAttr{
+		{"description", hclString(description)},
+		{"type", "string"},
+	}
+
+	if sensitive {
+		attrs = append(attrs, hclAttr{"sensitive", "true"})
+	}
+
+	if defaultValue != "" {
+		attrs = append(attrs, hclAttr{"default", defaultValue})
+	}
+
+	t.variables = append(t.variables, fmt.Sprintf("variable %v {\n%v}\n", hclString(name), renderHclAttrs(attrs, "  ")))
+	return "var." + name
+}
+
+func (t *terraformExport) warn(format string, args ...interface{}) {
+	t.warnings = append(t.warnings, fmt.Sprintf(format, args...))
+}
+
+func (t *terraformExport) exportMounts() error {
+	mounts, err := t.client.Sys().ListMounts()
+	if err != nil {
+		return fmt.Errorf("failed to list mounts: %w", err)
+	}
+
+	for _, path := range sortedKeys(mounts) {
+		mount := mounts[path]
+		if builtinMount(mount) {
+			continue
+		}
+
+		name := t.name("vault_mount", path)
+		t.mountRefs[path] = "vault_mount." + name + ".path"
+
+		attrs := []hclAttr{
+			{"path", hclString(strings.TrimSuffix(path, "/"))},
+			{"type", hclString(mount.Type)},
+		}
+
+		if mount.Description != "" {
+			attrs = append(attrs, hclAttr{"description", hclString(mount.Description)})
+		}
+
+		if mount.Config.DefaultLeaseTTL != 0 {
+			attrs = append(attrs, hclAttr{"default_lease_ttl_seconds", strconv.Itoa(mount.Config.DefaultLeaseTTL)})
+		}
+
+		if mount.Config.MaxLeaseTTL != 0 {
+			attrs = append(attrs, hclAttr{"max_lease_ttl_seconds", strconv.Itoa(mount.Config.MaxLeaseTTL)})
+		}
+
+		if mount.Local {
+			attrs = append(attrs, hclAttr{"local", "true"})
+		}
+
+		if mount.SealWrap {
+			attrs = append(attrs, hclAttr{"seal_wrap", "true"})
+		}
+
+		if len(mount.Options) > 0 {
+			attrs = append(attrs, hclAttr{"options", hclValue(mount.Options, "  ")})
+		}
+
+		t.resource("vault_mount", name, "", attrs)
+
+		switch mount.Type {
+		case "pki":
+			if err := t.exportPKI(path); err != nil {
+				return err
+			}
+		case "transit":
+			if err := t.exportTransit(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *terraformExport) exportAuths() error {
+	auths, err := t.client.Sys().ListAuth()
+	if err != nil {
+		return fmt.Errorf("failed to list auth methods: %w", err)
+	}
+
+	for _, path := range sortedKeys(auths) {
+		auth := auths[path]
+		if builtinMount(auth) {
+			continue
+		}
+
+		name := t.name("vault_auth_backend", path)
+		attrs := []hclAttr{
+			{"type", hclString(auth.Type)},
+			{"path", hclString(strings.TrimSuffix(path, "/"))},
+		}
+
+		if auth.Description != "" {
+			attrs = append(attrs, hclAttr{"description", hclString(auth.Description)})
+		}
+
+		if auth.Local {
+			attrs = append(attrs, hclAttr{"local", "true"})
+		}
+
+		var tune []hclAttr
+		if auth.Config.DefaultLeaseTTL != 0 {
+			tune = append(tune, hclAttr{"default_lease_ttl", hclString(fmt.Sprintf("%ds", auth.Config.DefaultLeaseTTL))})
+		}
+
+		if auth.Config.MaxLeaseTTL != 0 {
+			tune = append(tune, hclAttr{"max_lease_ttl", hclString(fmt.Sprintf("%ds", auth.Config.MaxLeaseTTL))})
+		}
+
+		if auth.Config.ListingVisibility != "" {
+			tune = append(tune, hclAttr{"listing_visibility", hclString(auth.Config.ListingVisibility)})
+		}
+
+		if auth.Config.TokenType != "" && auth.Config.TokenType != "default-service" {
+			tune = append(tune, hclAttr{"token_type", hclString(auth.Config.TokenType)})
+		}
+
+		if len(tune) > 0 {
+			attrs = append(attrs, hclBlock("tune", tune, "  "))
+		}
+
+		t.resource("vault_auth_backend", name, "", attrs)
+
+		if auth.Type == "userpass" {
+			if err := t.exportUserpass(path, "vault_auth_backend."+name+".path"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *terraformExport) exportPolicies() error {
+	names, err := t.client.Sys().ListPolicies()
+	if err != nil {
+		return fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	sort.Strings(names)
+	for _, policy := range names {
+		if policy == "root" || policy == "default" {
+			continue
+		}
+
+		rules, err := t.client.Sys().GetPolicy(policy)
+		if err != nil {
+			return fmt.Errorf("failed to read policy %v: %w", policy, err)
+		}
+
+		t.resource("vault_policy", t.name("vault_policy", policy), "", []hclAttr{
+			{"name", hclString(policy)},
+			{"policy", hclHeredoc(rules, "  ")},
+		})
+	}
+
+	return nil
+}
+
+// pickFields renders the given fields of an API response as attributes,
+// skipping absent and empty ones.
+func pickFields(data map[string]interface{}, fields []string, stringFields map[string]bool) []hclAttr {
+	var attrs []hclAttr
+	for _, field := range fields {
+		value, present := data[field]
+		if !present || value == nil {
+			continue
+		}
+
+		if list, ok := value.([]interface{}); ok && len(list) == 0 {
+			continue
+		}
+
+		if str, ok := value.(string); ok && str == "" {
+			continue
+		}
+
+		if number, ok := value.(json.Number); ok && stringFields[field] {
+			value = number.String()
+		}
+
+		attrs = append(attrs, hclAttr{field, hclValue(value, "  ")})
+	}
+
+	return attrs
+}
+
+func (t *terraformExport) exportPKI(path string) error {
+	backend := t.mountRefs[path]
+
+	issuers, err := listKeys(t.client, path+"issuers")
+	if err != nil {
+		t.warn("failed to list issuers of PKI mount %v: %v", path, err)
+	}
+
+	for _, id := range issuers {
+		data, err := readData(t.client, path+"issuer/"+id)
+		if err != nil || data == nil {
+			t.warn("failed to read issuer %v of PKI mount %v: %v", id, path, err)
+			continue
+		}
+
+		issuerName, _ := data["issuer_name"].(string)
+		label := issuerName
+		if label == "" {
+			label = id
+		}
+
+		subject := ""
+		if certificate, ok := data["certificate"].(string); ok {
+			if block, _ := pem.Decode([]byte(certificate)); block != nil {
+				if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+					subject = cert.Subject.String()
+				}
+			}
+		}
+
+		description := fmt.Sprintf("issuer %v on mount %v", label, path)
+		if subject != "" {
+			description += fmt.Sprintf(", subject %v", subject)
+		}
+
+		name := t.name("vault_pki_secret_backend_config_ca", path, label)
+		bundle := t.variable(name+"_pem_bundle", fmt.Sprintf("PEM bundle (certificate and private key) of %v.", description), true, "")
+		t.resource("vault_pki_secret_backend_config_ca", name, fmt.Sprintf("Imports %v (ID %v).", description, id), []hclAttr{
+			{"backend", backend},
+			{"pem_bundle", bundle},
+		})
+	}
+
+	roles, err := listKeys(t.client, path+"roles")
+	if err != nil {
+		t.warn("failed to list roles of PKI mount %v: %v", path, err)
+	}
+
+	for _, role := range roles {
+		data, err := readData(t.client, path+"roles/"+role)
+		if err != nil || data == nil {
+			t.warn("failed to read role %v of PKI mount %v: %v", role, path, err)
+			continue
+		}
+
+		attrs := []hclAttr{
+			{"backend", backend},
+			{"name", hclString(role)},
+		}
+
+		t.resource("vault_pki_secret_backend_role", t.name("vault_pki_secret_backend_role", path, role), "", append(attrs, pickFields(data, terraformPKIRoleFields, terraformPKIRoleStringFields)...))
+	}
+
+	return nil
+}
+
+func (t *terraformExport) exportTransit(path string) error {
+	keys, err := listKeys(t.client, path+"keys")
+	if err != nil {
+		t.warn("failed to list keys of transit mount %v: %v", path, err)
+	}
+
+	for _, key := range keys {
+		data, err := readData(t.client, path+"keys/"+key)
+		if err != nil || data == nil {
+			t.warn("failed to read key %v of transit mount %v: %v", key, path, err)
+			continue
+		}
+
+		attrs := []hclAttr{
+			{"backend", t.mountRefs[path]},
+			{"name", hclString(key)},
+		}
+
+		comment := fmt.Sprintf("Key %v is created with new key material; restore a backup to keep the original.", key)
+		t.resource("vault_transit_secret_backend_key", t.name("vault_transit_secret_backend_key", path, key), comment, append(attrs, pickFields(data, terraformTransitKeyFields, nil)...))
+	}
+
+	return nil
+}
+
+func (t *terraformExport) exportUserpass(path string, backend string) error {
+	users, err := listKeys(t.client, "auth/"+path+"users")
+	if err != nil {
+		t.warn("failed to list users of userpass auth method %v: %v", path, err)
+	}
+
+	for _, user := range users {
+		data, err := readData(t.client, "auth/"+path+"users/"+user)
+		if err != nil || data == nil {
+			t.warn("failed to read user %v of userpass auth method %v: %v", user, path, err)
+			continue
+		}
+
+		name := t.name("vault_generic_endpoint", path, user)
+		password := t.variable(name+"_password", fmt.Sprintf("Password of userpass user %v on auth method %v.", user, path), true, "")
+
+		fields := append([]hclAttr{{"password", password}}, pickFields(data, terraformUserpassFields, nil)...)
+		t.resource("vault_generic_endpoint", name, fmt.Sprintf("Userpass user %v.", user), []hclAttr{
+			{"path", fmt.Sprintf(`"auth/${%v}/users/%v"`, backend, hclEscape(user))},
+			{"disable_read", "true"},
+			{"ignore_absent_fields", "true"},
+			{"data_json", "jsonencode({\n" + renderHclAttrs(fields, "    ") + "  })"},
+		})
+	}
+
+	return nil
+}
+
+// renderHclAttrs renders attributes one per line at the given indentation,
+// aligning the equals signs as `terraform fmt` does, followed by any nested
+// blocks.
+func renderHclAttrs(attrs []hclAttr, indent string) string {
+	width := 0
+	for _, attr := range attrs {
+		if attr.Key != "" {
+			width = max(width, len(hclKey(attr.Key)))
+		}
+	}
+
+	var result string
+	for _, attr := range attrs {
+		if attr.Key != "" {
+			result += fmt.Sprintf("%v%-*v = %v\n", indent, width, hclKey(attr.Key), attr.Value)
+		}
+	}
+
+	for _, attr := range attrs {
+		if attr.Key == "" {
+			result += "\n" + attr.Value
+		}
+	}
+
+	return result
+}
+
+// hclBlock renders a nested block at the given indentation.
+func hclBlock(name string, attrs []hclAttr, indent string) hclAttr {
+	return hclAttr{Value: fmt.Sprintf("%v%v {\n%v%v}\n", indent, name, renderHclAttrs(attrs, indent+"  "), indent)}
+}
+
+func hclKey(key string) string {
+	if terraformIdentifier.MatchString(key) {
+		return key
+	}
+
+	return hclString(key)
+}
+
+// hclEscape escapes text for use inside a quoted HCL string, including
+// template sequences.
+func hclEscape(text string) string {
+	quoted := strconv.Quote(text)
+	quoted = quoted[1 : len(quoted)-1]
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
+
+func hclString(text string) string {
+	return `"` + hclEscape(text) + `"`
+}
+
+func hclHeredoc(text string, indent string) string {
+	text = strings.ReplaceAll(text, "${", "$${")
+	text = strings.ReplaceAll(text, "%{", "%%{")
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	return "<<-EOT\n" + text + indent + "EOT"
+}
+
+// hclValue renders a value decoded from an API response as an HCL
+// expression.
+func hclValue(value interface{}, indent string) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return hclString(typed)
+	case bool:
+		return strconv.FormatBool(typed)
+	case json.Number:
+		return typed.String()
+	case int:
+		return strconv.Itoa(typed)
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64)
+	case []interface{}:
+		var items []string
+		for _, item := range typed {
+			items = append(items, hclValue(item, indent))
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case []string:
+		var items []string
+		for _, item := range typed {
+			items = append(items, hclString(item))
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]string:
+		var attrs []hclAttr
+		for _, key := range sortedKeys(typed) {
+			attrs = append(attrs, hclAttr{key, hclString(typed[key])})
+		}
+
+		return "{\n" + renderHclAttrs(attrs, indent+"  ") + indent + "}"
+	case map[string]interface{}:
+		var attrs []hclAttr
+		for _, key := range sortedKeys(typed) {
+			attrs = append(attrs, hclAttr{key, hclValue(typed[key], indent+"  ")})
+		}
+
+		return "{\n" + renderHclAttrs(attrs, indent+"  ") + indent + "}"
+	}
+
+	return hclString(fmt.Sprintf("%v", value))
+}
+
+// ExportNodeTerraform writes the node's live state as Terraform
+// configuration into outDir, returning the paths of written files.
+func ExportNodeTerraform(node *Node, outDir string) ([]string, []string, error) {
+	main, variables, warnings, err := node.ExportTerraform()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var written []string
+	for name, contents := range map[string]string{TerraformMainName: main, TerraformVariablesName: variables} {
+		path := filepath.Join(outDir, name)
+		if err := writeExportFile(path, contents, 0o644); err != nil {
+			return written, warnings, err
+		}
+
+		written = append(written, path)
+	}
+
+	sort.Strings(written)
+	return written, warnings, nil
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TarGzWriter builds a gzip-compressed tarball from files on disk and from
+// in-memory contents.
+type TarGzWriter struct {
+	compressed *gzip.Writer
+	archive    *tar.Writer
+}
+
+func NewTarGzWriter(w io.Writer) *TarGzWriter {
+	compressed := gzip.NewWriter(w)
+	return &TarGzWriter{
+		compressed: compressed,
+		archive:    tar.NewWriter(compressed),
+	}
+}
+
+// AddFile adds a regular file with the given contents.
+func (t *TarGzWriter) AddFile(name string, contents []byte, mode os.FileMode) error {
+	header := &tar.Header{
+		Name:     name,
+		Mode:     int64(mode.Perm()),
+		Size:     int64(len(contents)),
+		Typeflag: tar.TypeReg,
+	}
+
+	if err := t.archive.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive header for %v: %w", name, err)
+	}
+
+	if _, err := t.archive.Write(contents); err != nil {
+		return fmt.Errorf("failed to archive %v: %w", name, err)
+	}
+
+	return nil
+}
+
+// AddTree adds the given entries, files or directories relative to root,
+// recursively. Missing entries are skipped; links are refused, as
+// ExtractTarGz does not restore them.
+func (t *TarGzWriter) AddTree(root string, entries []string) error {
+	for _, entry := range entries {
+		if _, err := os.Lstat(filepath.Join(root, entry)); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.WalkDir(filepath.Join(root, entry), func(path string, dirEntry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return fmt.Errorf("failed to compute relative path of %v: %w", path, err)
+			}
+
+			info, err := dirEntry.Info()
+			if err != nil {
+				return fmt.Errorf("failed to stat %v: %w", path, err)
+			}
+
+			if info.Mode()&os.ModeSymlink != 0 {
+				return fmt.Errorf("refusing to archive symlink (%v)", path)
+			}
+
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return fmt.Errorf("failed to build archive header for %v: %w", path, err)
+			}
+
+			header.Name = filepath.ToSlash(rel)
+			if err := t.archive.WriteHeader(header); err != nil {
+				return fmt.Errorf("failed to write archive header for %v: %w", path, err)
+			}
+
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("failed to open %v for reading: %w", path, err)
+			}
+			defer file.Close()
+
+			if _, err := io.Copy(t.archive, file); err != nil {
+				return fmt.Errorf("failed to archive %v: %w", path, err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *TarGzWriter) Close() error {
+	if err := t.archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return t.compressed.Close()
+}
+
+// WriteTarGz archives the given entries, files or directories relative to
+// root, into a gzip-compressed tarball. Missing entries are skipped.
+func WriteTarGz(w io.Writer, root string, entries []string) error {
+	archive := NewTarGzWriter(w)
+	if err := archive.AddTree(root, entries); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// ExtractTarGz extracts a gzip-compressed tarball into dst, creating it if
+// it does not yet exist. Archives may come from elsewhere, so entries which
+// would escape dst are rejected, as are symlinks and hard links, through
+// which later entries could be written outside of dst.
+func ExtractTarGz(r io.Reader, dst string) error {
+	uncompressed, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	defer uncompressed.Close()
+
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory (%v): %w", dst, err)
+	}
+
+	dst = filepath.Clean(dst)
+	archive := tar.NewReader(uncompressed)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(header.Name))
+		if target != dst && !strings.HasPrefix(target, dst+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry `%v` escapes the destination directory", header.Name)
+		}
+
+		mode := os.FileMode(header.Mode).Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0o700); err != nil {
+				return fmt.Errorf("failed to create directory (%v): %w", target, err)
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			return fmt.Errorf("archive entry `%v` is a link, which is not supported", header.Name)
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return fmt.Errorf("failed to create directory (%v): %w", filepath.Dir(target), err)
+			}
+
+			file, err := os.OpenFile(target, os.O_RDWR|os.O_CREATE|os.O_TRUNC, mode)
+			if err != nil {
+				return fmt.Errorf("failed to open %v for writing: %w", target, err)
+			}
+
+			_, err = io.Copy(file, archive)
+			file.Close()
+			if err != nil {
+				return fmt.Errorf("failed to extract %v: %w", target, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CopyFile copies a single regular file, preserving its permissions.
+func CopyFile(src string, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat source file (%v): %w", src, err)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file (%v) for reading: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to open destination file (%v) for writing: %w", dst, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("failed to copy %v to %v: %w", src, dst, err)
+	}
+
+	return nil
+}
+
+// CopyDir recursively copies the contents of src into dst, creating dst if
+// it does not yet exist. Symlinks are recreated rather than followed.
+func CopyDir(src string, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("failed to compute relative path of %v: %w", path, err)
+		}
+
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case entry.IsDir():
+			info, err := entry.Info()
+			if err != nil {
+				return fmt.Errorf("failed to stat directory (%v): %w", path, err)
+			}
+
+			if err := os.MkdirAll(target, info.Mode().Perm()|0o700); err != nil {
+				return fmt.Errorf("failed to create directory (%v): %w", target, err)
+			}
+		case entry.Type()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink (%v): %w", path, err)
+			}
+
+			if err := os.Symlink(link, target); err != nil {
+				return fmt.Errorf("failed to create symlink (%v): %w", target, err)
+			}
+		case entry.Type().IsRegular():
+			if err := CopyFile(path, target); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"strings"
+)
+
+// LineDiff returns a simple line-oriented diff between two strings, with
+// removed lines prefixed by `-`, added lines prefixed by `+` and unchanged
+// lines prefixed by a space. An empty string is returned when both inputs
+// are identical.
+func LineDiff(before string, after string) string {
+	if before == after {
+		return ""
+	}
+
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// Longest common subsequence table; config files and policies are small
+	// enough that the quadratic approach is fine.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		lines = append(lines, "- "+a[i])
+	}
+
+	for ; j < len(b); j++ {
+		lines = append(lines, "+ "+b[j])
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ChangedLines returns only the added and removed lines of a diff produced
+// by LineDiff, without their prefixes.
+func ChangedLines(diff string) []string {
+	var results []string
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "+ ") {
+			results = append(results, line[2:])
+		}
+	}
+
+	return results
+}
+
+// splitLines splits text into lines, with empty text having none.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}